@@ -0,0 +1,393 @@
+// Command mdtable reads CSV or TSV data from files or stdin and writes it to stdout as a Markdown table, or any other format supported by the mdtable package.
+//
+// Usage:
+//
+//	mdtable [flags] [file ...]
+//
+// Exit codes: 0 on success, 1 when the input could not be read or converted, 2 on invalid flags or configuration.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"mdtable"
+)
+
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsageOrConf = 2
+)
+
+var alignNames = map[string]mdtable.Align{
+	"center": mdtable.Center,
+	"left":   mdtable.Left,
+	"right":  mdtable.Right,
+	"auto":   mdtable.Auto,
+}
+
+var sortOptionNames = map[string]mdtable.ColumnSortOption{
+	"none":       mdtable.None,
+	"asc":        mdtable.Ascending,
+	"ascending":  mdtable.Ascending,
+	"desc":       mdtable.Descending,
+	"descending": mdtable.Descending,
+}
+
+var raggedRowPolicyNames = map[string]mdtable.RaggedRowPolicy{
+	"error":    mdtable.RaggedError,
+	"pad":      mdtable.RaggedPad,
+	"truncate": mdtable.RaggedTruncate,
+	"extend":   mdtable.RaggedExtendHeader,
+}
+
+var matchKindNames = map[string]mdtable.MatchKind{
+	"exact":   mdtable.MatchExact,
+	"fold":    mdtable.MatchFold,
+	"glob":    mdtable.MatchGlob,
+	"regexp":  mdtable.MatchRegexp,
+	"indices": mdtable.MatchIndices,
+}
+
+var borderStyleNames = map[string]mdtable.BorderStyle{
+	"single":  mdtable.Single,
+	"double":  mdtable.Double,
+	"rounded": mdtable.Rounded,
+	"ascii":   mdtable.ASCII,
+}
+
+// flag that can be repeated, each value may hold several comma-separated items
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// flag that can be repeated, each value is a single item that may contain commas
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, " ")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Values of the flags that make up the Config
+type configFlags struct {
+	align          string
+	columnAlign    listFlag
+	caption        string
+	placeholder    string
+	compact        bool
+	renderer       string
+	borderStyle    string
+	headerColor    string
+	cellColor      string
+	borderColor    string
+	exclude        listFlag
+	excludeMatch   repeatedFlag
+	includeMatch   repeatedFlag
+	columns        listFlag
+	pin            listFlag
+	sortColumns    string
+	sortComparator string
+	sortRows       listFlag
+	raggedRows     string
+	verbose        bool
+}
+
+// Run the command and return its exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mdtable", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: mdtable [flags] [file ...]\n\nConvert CSV/TSV from the given files, or stdin if none are given, into Markdown tables.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+
+	var cfgFlags configFlags
+	flags.StringVar(&cfgFlags.align, "align", "center", "alignment of all columns: center, left, right or auto")
+	flags.Var(&cfgFlags.columnAlign, "column-align", "alignment of a single column as `name=align`, may be repeated or comma-separated")
+	flags.StringVar(&cfgFlags.caption, "caption", "", "caption of the table, rendered as an HTML comment")
+	flags.StringVar(&cfgFlags.placeholder, "empty-placeholder", "", "data line rendered when the input only has a header line, e.g. \"_No results_\"")
+	flags.BoolVar(&cfgFlags.compact, "compact", false, "render the compact version of the table")
+	flags.StringVar(&cfgFlags.renderer, "renderer", mdtable.DefaultRenderer, "output format: "+strings.Join(mdtable.RendererNames(), ", "))
+	flags.StringVar(&cfgFlags.borderStyle, "border-style", "single", "borders of the terminal renderer: single, double, rounded or ascii")
+	flags.StringVar(&cfgFlags.headerColor, "header-color", "", "ANSI SGR parameters coloring the header line of the terminal renderer, e.g. \"1;36\"")
+	flags.StringVar(&cfgFlags.cellColor, "cell-color", "", "ANSI SGR parameters coloring the data lines of the terminal renderer")
+	flags.StringVar(&cfgFlags.borderColor, "border-color", "", "ANSI SGR parameters coloring the borders of the terminal renderer")
+	flags.Var(&cfgFlags.exclude, "exclude", "`columns` to exclude, may be repeated or comma-separated")
+	flags.Var(&cfgFlags.excludeMatch, "exclude-match", "exclude the columns matching `kind:pattern`, kind being exact, fold, glob, regexp or indices, may be repeated")
+	flags.Var(&cfgFlags.includeMatch, "include-match", "only keep the columns matching `kind:pattern`, kind being exact, fold, glob, regexp or indices, may be repeated")
+	flags.Var(&cfgFlags.columns, "columns", "`columns` to render, in this order, may be repeated or comma-separated. Overrides --sort-columns")
+	flags.Var(&cfgFlags.pin, "pin", "`columns` rendered first, in this order, may be repeated or comma-separated")
+	flags.StringVar(&cfgFlags.sortColumns, "sort-columns", "none", "sort the columns by header name: none, asc or desc")
+	flags.StringVar(&cfgFlags.sortComparator, "sort-comparator", "", "comparator used to sort columns: lexical, numeric, natural, semver, datetime or duration")
+	flags.Var(&cfgFlags.sortRows, "sort-rows", "sort the rows by a column as `name[:asc|desc[:comparator]]`, may be repeated or comma-separated")
+	flags.StringVar(&cfgFlags.raggedRows, "ragged-rows", "error", "lines with more or fewer fields than the header: error, pad, truncate or extend")
+	flags.BoolVar(&cfgFlags.verbose, "verbose", false, "log detailed diagnostic messages")
+	delimiter := flags.String("delimiter", ",", "field delimiter of the input")
+	tsv := flags.Bool("tsv", false, "read tab-separated input, same as --delimiter='\\t'")
+	comment := flags.String("comment", "", "ignore input lines starting with this character")
+	lazyQuotes := flags.Bool("lazy-quotes", false, "allow quotes in unquoted fields and unescaped quotes in quoted fields")
+	noHeader := flags.Bool("no-header", false, "the input has no header line, columns are named \"Column 1\", \"Column 2\", ...")
+	trimSpace := flags.Bool("trim-space", false, "trim the whitespace surrounding every field")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsageOrConf
+	}
+
+	cfg, err := cfgFlags.config()
+
+	// diagnostics go to the same stream as the errors of the command
+	logLevel := slog.LevelWarn
+	if cfgFlags.verbose {
+		logLevel = slog.LevelDebug
+	}
+	logHandler := slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: logLevel})
+	configWarnings := map[string]bool{}
+	cfg.Logger = slog.New(configWarningsHandler{Handler: logHandler, warnings: configWarnings})
+	if err == nil {
+		// fail before reading any input. Config warnings are logged here once and left out of the conversion of every input
+		validationCfg := cfg
+		validationCfg.Logger = slog.New(configWarningsHandler{Handler: logHandler, warnings: configWarnings, validating: true})
+		validationCfg.VerboseLogging = false
+		err = mdtable.ValidateConfig(validationCfg)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "mdtable: invalid configuration: %s\n", err)
+		return exitUsageOrConf
+	}
+
+	csvOpts := mdtable.CSVOptions{LazyQuotes: *lazyQuotes, NoHeader: *noHeader, TrimSpace: *trimSpace, AllowRagged: cfg.RaggedRows != mdtable.RaggedError}
+	if csvOpts.Delimiter, err = singleRune("delimiter", *delimiter); err == nil && *tsv {
+		csvOpts.Delimiter = '\t'
+
+		// --tsv would silently override the delimiter
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "delimiter" {
+				err = errors.New("--delimiter and --tsv can't be used together")
+			}
+		})
+	}
+	if err == nil && *comment != "" {
+		csvOpts.Comment, err = singleRune("comment", *comment)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "mdtable: %s\n", err)
+		return exitUsageOrConf
+	}
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	for idx, input := range inputs {
+		table, err := convertInput(input, stdin, csvOpts, cfg)
+		if err != nil {
+			fmt.Fprintf(stderr, "mdtable: %s\n", err)
+
+			// configured columns that don't exist in the input are configuration errors as well
+			if errors.Is(err, mdtable.ErrInvalidConfig) {
+				return exitUsageOrConf
+			}
+			return exitFailure
+		}
+
+		// tables of several inputs are separated by an empty line
+		if idx > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintln(stdout, table)
+	}
+
+	return exitOK
+}
+
+// Build the Config from the values of the flags
+func (f configFlags) config() (mdtable.Config, error) {
+	var cfg mdtable.Config
+	var ok bool
+
+	if cfg.Align, ok = alignNames[strings.ToLower(f.align)]; !ok {
+		return cfg, fmt.Errorf("unknown align %q", f.align)
+	}
+
+	for _, item := range f.columnAlign {
+		colName, colAlign, found := strings.Cut(item, "=")
+		if !found {
+			return cfg, fmt.Errorf("column align %q must be formatted as name=align", item)
+		}
+
+		if cfg.ColumnAlign == nil {
+			cfg.ColumnAlign = map[string]mdtable.Align{}
+		}
+		if cfg.ColumnAlign[colName], ok = alignNames[strings.ToLower(colAlign)]; !ok {
+			return cfg, fmt.Errorf("unknown align %q of column %q", colAlign, colName)
+		}
+	}
+
+	for _, item := range f.excludeMatch {
+		matcher, err := columnMatcher(item)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.ExcludeMatchers = append(cfg.ExcludeMatchers, matcher)
+	}
+
+	for _, item := range f.includeMatch {
+		matcher, err := columnMatcher(item)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.IncludeMatchers = append(cfg.IncludeMatchers, matcher)
+	}
+
+	if cfg.SortColumns, ok = sortOptionNames[strings.ToLower(f.sortColumns)]; !ok {
+		return cfg, fmt.Errorf("unknown sort columns option %q", f.sortColumns)
+	}
+
+	for _, item := range f.sortRows {
+		parts := strings.Split(item, ":")
+		if len(parts) > 3 {
+			return cfg, fmt.Errorf("sort rows %q must be formatted as name[:asc|desc[:comparator]]", item)
+		}
+
+		key := mdtable.RowSortKey{Column: parts[0], Order: mdtable.Ascending}
+		if len(parts) > 1 {
+			if key.Order, ok = sortOptionNames[strings.ToLower(parts[1])]; !ok {
+				return cfg, fmt.Errorf("unknown sort order %q of column %q", parts[1], parts[0])
+			}
+		}
+		if len(parts) > 2 {
+			key.Comparator = mdtable.Comparator(strings.ToLower(parts[2]))
+		}
+		cfg.SortRows = append(cfg.SortRows, key)
+	}
+
+	if cfg.RaggedRows, ok = raggedRowPolicyNames[strings.ToLower(f.raggedRows)]; !ok {
+		return cfg, fmt.Errorf("unknown ragged rows policy %q", f.raggedRows)
+	}
+
+	if cfg.BorderStyle, ok = borderStyleNames[strings.ToLower(f.borderStyle)]; !ok {
+		return cfg, fmt.Errorf("unknown border style %q", f.borderStyle)
+	}
+
+	cfg.Caption = f.caption
+	cfg.EmptyPlaceholder = f.placeholder
+	cfg.Compact = f.compact
+	cfg.Renderer = f.renderer
+	cfg.HeaderColor = mdtable.Color(f.headerColor)
+	cfg.CellColor = mdtable.Color(f.cellColor)
+	cfg.BorderColor = mdtable.Color(f.borderColor)
+	cfg.ExcludedColumns = f.exclude
+	cfg.ColumnOrder = f.columns
+	cfg.PinnedColumns = f.pin
+	cfg.SortComparator = mdtable.Comparator(strings.ToLower(f.sortComparator))
+	cfg.VerboseLogging = f.verbose
+
+	return cfg, nil
+}
+
+// Parse a column matcher formatted as kind:pattern
+func columnMatcher(item string) (mdtable.ColumnMatcher, error) {
+	kindName, pattern, found := strings.Cut(item, ":")
+	kind, ok := matchKindNames[strings.ToLower(kindName)]
+	if !found || !ok {
+		return mdtable.ColumnMatcher{}, fmt.Errorf("column pattern %q must be formatted as kind:pattern, kind being exact, fold, glob, regexp or indices", item)
+	}
+
+	return mdtable.ColumnMatcher{Kind: kind, Pattern: pattern}, nil
+}
+
+// Read the records of a file, or of stdin if the name is "-", and convert them into a Markdown table
+func convertInput(name string, stdin io.Reader, csvOpts mdtable.CSVOptions, cfg mdtable.Config) (string, error) {
+	input := stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	records, err := mdtable.ReadCSV(input, csvOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	table, err := mdtable.Convert(records, cfg)
+	if errors.Is(err, mdtable.ErrNoRecords) {
+		return "", fmt.Errorf("%s does not contain any records", name)
+	}
+
+	return table, err
+}
+
+// Handler leaving out the config warnings that were logged when validating the config, since converting an input logs them again
+type configWarningsHandler struct {
+	slog.Handler
+
+	// messages of the config warnings, shared by the handlers used for validating and for converting
+	warnings map[string]bool
+
+	// whether the config is being validated, collecting the warnings instead of leaving them out
+	validating bool
+}
+
+func (h configWarningsHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level == slog.LevelWarn {
+		if h.validating {
+			h.warnings[record.Message] = true
+		} else if h.warnings[record.Message] {
+			return nil
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h configWarningsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return configWarningsHandler{h.Handler.WithAttrs(attrs), h.warnings, h.validating}
+}
+
+func (h configWarningsHandler) WithGroup(name string) slog.Handler {
+	return configWarningsHandler{h.Handler.WithGroup(name), h.warnings, h.validating}
+}
+
+// Get the only character of a flag value
+func singleRune(flagName string, value string) (rune, error) {
+	runes := []rune(value)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("%s must be a single character, received %q", flagName, value)
+	}
+	return runes[0], nil
+}
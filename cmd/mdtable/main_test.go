@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("Name\tScore\nBob \t7\n Alice\t10\n")
+
+	expected := `|Name|Score|
+|:-|-:|
+|Alice|10|
+|Bob|7|
+`
+
+	code := run([]string{"--tsv", "--trim-space", "--compact", "--align", "auto", "--sort-rows", "Name:asc"}, stdin, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Empty(t, stderr.String(), "Nothing should be written to stderr")
+	assert.Equal(t, expected, stdout.String(), "The two strings should be the same")
+}
+
+func TestRunFiles(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	second := filepath.Join(dir, "second.csv")
+	assert.Nil(t, os.WriteFile(first, []byte("a;b;secret\n1;2;3\n"), 0o644))
+	assert.Nil(t, os.WriteFile(second, []byte("c\n4\n"), 0o644))
+
+	expected := `<!-- Numbers -->
+| a  | b  |
+| :- | :- |
+| 1  | 2  |
+
+<!-- Numbers -->
+| c  |
+| :- |
+| 4  |
+`
+
+	code := run([]string{"--delimiter", ";", "--align", "left", "--caption", "Numbers", "--exclude", "secret", first, second}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, expected, stdout.String(), "The two strings should be the same")
+}
+
+func TestRunRaggedRows(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	expected := `|a|b|
+|:-:|:-:|
+|1||
+|2|3|
+`
+
+	code := run([]string{"--compact", "--ragged-rows", "truncate"}, strings.NewReader("a,b\n1\n2,3,4\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, expected, stdout.String(), "The two strings should be the same")
+}
+
+func TestRunEmptyInput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"--compact", "--empty-placeholder", "_No results_"}, strings.NewReader("a,b\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, "|a|b|\n|:-:|:-:|\n|_No results_||\n", stdout.String(), "The two strings should be the same")
+
+	stdout.Reset()
+	code = run(nil, strings.NewReader(""), &stdout, &stderr)
+
+	assert.Equal(t, exitFailure, code, "Empty input should fail")
+	assert.Contains(t, stderr.String(), "does not contain any records", "Empty input should be reported")
+}
+
+func TestRunColumnOrder(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	expected := `|id|c|a|
+|:-:|:-:|:-:|
+|1|4|2|
+`
+
+	code := run([]string{"--compact", "--pin", "id", "--columns", "c,a"}, strings.NewReader("a,b,c,id\n2,3,4,1\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, expected, stdout.String(), "The two strings should be the same")
+}
+
+func TestRunColumnMatchers(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	expected := `|id|name|
+|:-:|:-:|
+|1|Alice|
+`
+
+	code := run([]string{"--compact", "--exclude-match", "glob:_*", "--include-match", "indices:0-2,4"}, strings.NewReader("id,name,_meta,score,_x\n1,Alice,m,7,x\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, expected, stdout.String(), "The two strings should be the same")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--exclude-match", "internal_*"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "Patterns without a kind should be configuration errors")
+}
+
+func TestRunLogsWarningsOnce(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"--verbose", "--sort-comparator", "numeric"}, strings.NewReader("a\n1\n"), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, 1, strings.Count(stderr.String(), "Sort comparator only works"), "Config warnings should be logged once")
+	assert.Equal(t, 1, strings.Count(stderr.String(), "Validating config"), "Verbose messages should be logged once")
+
+	input := filepath.Join(t.TempDir(), "x.csv")
+	assert.Nil(t, os.WriteFile(input, []byte("a\n1\n"), 0o644))
+	stderr.Reset()
+
+	code = run([]string{"--sort-comparator", "numeric", input, input}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, "Exit code should be 0")
+	assert.Equal(t, 1, strings.Count(stderr.String(), "Sort comparator only works"), "Config warnings should be logged once for all inputs")
+}
+
+func TestRunExitCodes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitUsageOrConf, run([]string{"--align", "diagonal"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "Unknown align should be a configuration error")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--sort-comparator", "roman"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "ValidateConfig errors should be configuration errors")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--sort-rows", "missing"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "Unknown columns should be configuration errors")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--no-such-flag"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "Unknown flags should be usage errors")
+	assert.Equal(t, exitFailure, run([]string{filepath.Join(t.TempDir(), "missing.csv")}, nil, &stdout, &stderr), "Missing files should be failures")
+	assert.Equal(t, exitFailure, run(nil, strings.NewReader(""), &stdout, &stderr), "Empty input should be a failure")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--delimiter", ";;"}, strings.NewReader("a\n1\n"), &stdout, &stderr), "Delimiters longer than one character should be usage errors")
+	assert.Equal(t, exitUsageOrConf, run([]string{"--delimiter", ";", "--tsv"}, strings.NewReader("a;b\n1;2\n"), &stdout, &stderr), "Delimiter and TSV together should be usage errors")
+}
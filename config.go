@@ -1,21 +1,48 @@
 package mdtable
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
 	"strings"
 )
 
+// Problems of a Config, wrapped by the errors of ValidateConfig and Convert
+var (
+	ErrInvalidAlign       = errors.New("align value is out of range, please choose in range [0-3]")
+	ErrUnknownRenderer    = errors.New("renderer is not registered")
+	ErrInvalidBorderStyle = errors.New("border style value is out of range, please choose in range [0-3]")
+	ErrInvalidSortColumns = errors.New("sort columns value is out of range, please choose in range [0-3]")
+	ErrMissingSortFunc    = errors.New("sort type is set to Custom but SortFunc was not set")
+	ErrInvalidRaggedRows  = errors.New("ragged rows value is out of range, please choose in range [0-3]")
+	ErrUnknownComparator  = errors.New("not a built-in comparator")
+	ErrInvalidSortOrder   = errors.New("row sort order must be Ascending or Descending")
+	ErrUnknownColumn      = errors.New("column does not exist in the header line")
+)
+
 type Align int
 
 const (
 	Center Align = 0
 	Left   Align = 1
 	Right  Align = 2
+	Auto   Align = 3
 )
 
+var alignName = map[Align]string{
+	Center: "Center",
+	Left:   "Left",
+	Right:  "Right",
+	Auto:   "Auto",
+}
+
+func (align Align) String() string {
+	return alignName[align]
+}
+
 type ColumnSortOption int
 
 const (
@@ -38,109 +65,387 @@ func (cso ColumnSortOption) String() string {
 
 type ColumnSortFunction func(a string, b string) int
 
+// Sort key for the data lines of the table
+type RowSortKey struct {
+	// Name of the column to sort by
+	Column string
+
+	// Direction of the sort, either Ascending or Descending
+	Order ColumnSortOption
+
+	// Name of the built-in compare function for the values of the column. Lexical is used if not set
+	Comparator Comparator
+
+	// Custom compare function for the values of the column. Takes precedence over Comparator
+	SortFunction ColumnSortFunction
+}
+
 type Config struct {
-	// Align the rendered content for the Markdown table. 0 = Center, 1 = Left, 2 = Right
+	// Align the rendered content for the Markdown table. 0 = Center, 1 = Left, 2 = Right, 3 = Auto.
+	// Auto right-aligns numeric columns, centers boolean/flag columns and left-aligns everything else
 	Align Align
 
+	// Alignment of individual columns, keyed by header name. Columns not listed here fall back to Align
+	ColumnAlign map[string]Align
+
+	// Alignment of each column, indexed like the header line (internal)
+	columnAligns []Align
+
 	// Caption of the table (as an HTML comment)
 	Caption string
 
 	// Should the markdown table be the compact version
 	Compact bool
 
+	// Name of the renderer producing the output format, e.g. "html", "rst-grid" or "latex". Markdown is rendered if not set
+	Renderer string
+
+	// Style of the borders drawn by the terminal renderer. 0 = Single, 1 = Double, 2 = Rounded, 3 = ASCII
+	BorderStyle BorderStyle
+
+	// Colors of the header line, data lines and borders drawn by the terminal renderer. Nothing is colored if not set
+	HeaderColor Color
+	CellColor   Color
+	BorderColor Color
+
 	// List of columns to be excluded from table construction
 	ExcludedColumns []string
 
+	// Columns to exclude, matched by name, pattern or position
+	ExcludeMatchers []ColumnMatcher
+
+	// Columns to keep, matched by name, pattern or position. Columns not matched by any of them are excluded. All columns are kept if not set
+	IncludeMatchers []ColumnMatcher
+
 	// Indices of excluded columns (internal)
 	excludedColumnsIndices []int
 
 	// Indices of columns to convert to
 	orderedColumnsIndices []int
 
+	// Columns to render, by header name and in this order. Other columns are left out and SortColumns is ignored. All columns are rendered if not set
+	ColumnOrder []string
+
+	// Columns rendered first, in this order, followed by the other columns. Pinned columns are rendered even when ColumnOrder doesn't list them
+	PinnedColumns []string
+
 	// Should the columns be sorted and how?
 	SortColumns ColumnSortOption
 
 	// Custom sort function
 	SortFunction ColumnSortFunction
 
-	// Log detailed diagnostic messages when running the program.
+	// Name of the built-in compare function used when SortColumns is Ascending or Descending. Lexical is used if not set
+	SortComparator Comparator
+
+	// Sort the data lines by one or more columns, in order of priority. The header line always stays on top
+	SortRows []RowSortKey
+
+	// Data line rendered in the first column of a table that only has a header line, e.g. "_No results_". Header-only tables have no data lines if not set
+	EmptyPlaceholder string
+
+	// What to do with data lines that have more or fewer fields than the header line. An error is returned if not set
+	RaggedRows RaggedRowPolicy
+
+	// Logger receiving warnings and diagnostic messages. slog.Default() is used if not set
+	Logger *slog.Logger
+
+	// Log detailed diagnostic messages at debug level. Without a Logger they go to the handler of the default logger, whose level is left untouched
 	VerboseLogging bool
+
+	// Logger receiving the diagnostic messages, nil if verbose logging is disabled (internal)
+	debugLogger *slog.Logger
+
+	// Whether the config warnings were already logged, e.g. once for a whole document (internal)
+	configWarningsLogged bool
+}
+
+// Get the logger receiving the warnings of the config
+func (cfg Config) logger() *slog.Logger {
+	if cfg.Logger != nil {
+		return cfg.Logger
+	}
+
+	return slog.Default()
+}
+
+// Populate debugLogger in Config object if verbose logging is enabled. Without a Logger, debug messages go to the handler of
+// the default logger whatever its level is, the default logger itself is left untouched
+func populateDebugLogger(cfg Config) Config {
+	switch {
+	case !cfg.VerboseLogging:
+		cfg.debugLogger = nil
+	case cfg.Logger != nil:
+		cfg.debugLogger = cfg.Logger
+	default:
+		cfg.debugLogger = slog.New(debugHandler{slog.Default().Handler()})
+	}
+
+	return cfg
+}
+
+// Log a diagnostic message if verbose logging is enabled
+func (cfg Config) debug(msg string, args ...any) {
+	if cfg.debugLogger != nil {
+		cfg.debugLogger.Debug(msg, args...)
+	}
+}
+
+// Handler passing debug messages on to a handler that may only be enabled for higher levels
+type debugHandler struct {
+	slog.Handler
+}
+
+func (debugHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h debugHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return debugHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h debugHandler) WithGroup(name string) slog.Handler {
+	return debugHandler{h.Handler.WithGroup(name)}
 }
 
 // Validate the Config object passed as parameter.
-// An error will be returned in case the configuration was invalid.
+// An error will be returned in case the configuration was invalid, joining every problem that was found. Use errors.Is to look for specific problems.
 func ValidateConfig(cfg Config) error {
 
-	cfgWarnings := []string{}
+	cfg = populateDebugLogger(cfg)
+	cfgWarnings, err := validateConfig(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	logConfigWarnings(cfg, cfgWarnings)
+
+	return nil
+}
+
+// Validate the Config object and collect the warnings about settings that will be ignored
+func validateConfig(cfg Config) ([]Warning, error) {
+
+	var cfgWarnings []Warning
+
+	cfg.debug("Validating config 🤔")
+
+	var cfgErrs []error
+
+	if cfg.Align < Center || cfg.Align > Auto {
+		cfgErrs = append(cfgErrs, ErrInvalidAlign)
+	}
+
+	for _, colName := range slices.Sorted(maps.Keys(cfg.ColumnAlign)) {
+		if align := cfg.ColumnAlign[colName]; align < Center || align > Auto {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q: %w", colName, ErrInvalidAlign))
+		}
+	}
 
-	if cfg.VerboseLogging {
-		slog.SetLogLoggerLevel(slog.LevelDebug)
-		slog.Debug("Validating config 🤔")
+	if _, ok := lookupRenderer(cfg.Renderer); !ok {
+		cfgErrs = append(cfgErrs, fmt.Errorf("%w: %q, please choose one of %s", ErrUnknownRenderer, cfg.Renderer, strings.Join(RendererNames(), ", ")))
 	}
 
-	if cfg.Align < Center || cfg.Align > Right {
-		return errors.New("align value is out of range, please choose in range [0-2]")
+	if cfg.BorderStyle < Single || cfg.BorderStyle > ASCII {
+		cfgErrs = append(cfgErrs, ErrInvalidBorderStyle)
 	}
 
 	if cfg.SortColumns < None || cfg.SortColumns > Custom {
-		return errors.New("sort columns value is out of range, please choose in range [0-3]")
+		cfgErrs = append(cfgErrs, ErrInvalidSortColumns)
 	}
 
 	// custom sort but no custom sort function was provided, will affect sorting columns
 	if cfg.SortColumns == Custom && cfg.SortFunction == nil {
-		return errors.New("sort type is set to Custom but SortFunc was not set.")
+		cfgErrs = append(cfgErrs, ErrMissingSortFunc)
+	}
+
+	if cfg.RaggedRows < RaggedError || cfg.RaggedRows > RaggedExtendHeader {
+		cfgErrs = append(cfgErrs, ErrInvalidRaggedRows)
+	}
+
+	if _, ok := comparatorFunction(cfg.SortComparator); !ok {
+		cfgErrs = append(cfgErrs, fmt.Errorf("sort comparator %q: %w", cfg.SortComparator, ErrUnknownComparator))
+	}
+
+	for _, matcher := range slices.Concat(cfg.ExcludeMatchers, cfg.IncludeMatchers) {
+		if _, err := matcher.compile(); err != nil {
+			cfgErrs = append(cfgErrs, err)
+		}
+	}
+
+	for _, key := range cfg.SortRows {
+		if key.Order != Ascending && key.Order != Descending {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q: %w, received %s", key.Column, ErrInvalidSortOrder, key.Order))
+		}
+
+		if _, ok := comparatorFunction(key.Comparator); !ok {
+			cfgErrs = append(cfgErrs, fmt.Errorf("comparator %q of column %q: %w", key.Comparator, key.Column, ErrUnknownComparator))
+		}
+
+		if key.Comparator != "" && key.SortFunction != nil {
+			cfgWarnings = append(cfgWarnings, Warning{
+				Code:    WarnComparatorIgnored,
+				Message: fmt.Sprintf("Row sort key of column %q has both Comparator and SortFunction set, ignoring Comparator.", key.Column),
+				Column:  key.Column,
+			})
+		}
+	}
+
+	// comparator passed in but columns are not sorted by a built-in sort
+	if cfg.SortComparator != "" && cfg.SortColumns != Ascending && cfg.SortColumns != Descending {
+		cfgWarnings = append(cfgWarnings, Warning{
+			Code:    WarnSortComparatorIgnored,
+			Message: fmt.Sprintf("Sort comparator only works when SortColumns is set to Ascending or Descending. SortColumns received is %s, ignoring SortComparator.", cfg.SortColumns),
+		})
+	}
+
+	// explicit column order but columns should be sorted as well
+	if len(cfg.ColumnOrder) > 0 && cfg.SortColumns != None {
+		cfgWarnings = append(cfgWarnings, Warning{
+			Code:    WarnSortColumnsIgnored,
+			Message: fmt.Sprintf("Sort columns does not apply when ColumnOrder is set. SortColumns received is %s, ignoring SortColumns.", cfg.SortColumns),
+		})
 	}
 
 	// function passed in but not sort type is not custom
 	if cfg.SortColumns != Custom && cfg.SortFunction != nil {
-		cfgWarnings = append(cfgWarnings, fmt.Sprintf("Sort function only works when SortColumns is set to Custom. SortColumns received is %s, ignoring SortFunc.", cfg.SortColumns))
+		cfgWarnings = append(cfgWarnings, Warning{
+			Code:    WarnSortFunctionIgnored,
+			Message: fmt.Sprintf("Sort function only works when SortColumns is set to Custom. SortColumns received is %s, ignoring SortFunc.", cfg.SortColumns),
+		})
+	}
+
+	if len(cfgErrs) > 0 {
+		return nil, errors.Join(cfgErrs...)
 	}
 
+	return cfgWarnings, nil
+}
+
+// Log the warnings of a config in a single message
+func logConfigWarnings(cfg Config, cfgWarnings []Warning) {
 	if len(cfgWarnings) > 0 {
 		// config contains warnings, let's warn user but also continue the execution
-		warnings := strings.Join(cfgWarnings, "\n")
-		warningMsg := fmt.Sprintf("Config contains some warnings:\n%s", warnings)
-		slog.Warn(warningMsg)
-	} else if cfg.VerboseLogging {
-		slog.Debug("Config is valid ✅")
+		messages := make([]string, len(cfgWarnings))
+		for idx, warning := range cfgWarnings {
+			messages[idx] = warning.Message
+		}
+		warningMsg := fmt.Sprintf("Config contains some warnings:\n%s", strings.Join(messages, "\n"))
+		cfg.logger().Warn(warningMsg)
+	} else {
+		cfg.debug("Config is valid ✅")
 	}
+}
 
-	return nil
+// Validate the parts of the Config object that refer to columns by name against the header line.
+func validateColumnNames(cfg Config, headerLine []string) error {
+	var cfgErrs []error
+
+	for _, colName := range slices.Sorted(maps.Keys(cfg.ColumnAlign)) {
+		if !slices.Contains(headerLine, colName) {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q in ColumnAlign: %w", colName, ErrUnknownColumn))
+		}
+	}
+
+	for _, key := range cfg.SortRows {
+		if !slices.Contains(headerLine, key.Column) {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q in SortRows: %w", key.Column, ErrUnknownColumn))
+		}
+	}
+
+	for _, colName := range cfg.ColumnOrder {
+		if !slices.Contains(headerLine, colName) {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q in ColumnOrder: %w", colName, ErrUnknownColumn))
+		}
+	}
+
+	for _, colName := range cfg.PinnedColumns {
+		if !slices.Contains(headerLine, colName) {
+			cfgErrs = append(cfgErrs, fmt.Errorf("column %q in PinnedColumns: %w", colName, ErrUnknownColumn))
+		}
+	}
+
+	return errors.Join(cfgErrs...)
+}
+
+// Populate columnAligns in Config object. Columns without an explicit alignment use the global Align
+func populateColumnAligns(cfg Config, headerLine []string) Config {
+	cfg.columnAligns = make([]Align, len(headerLine))
+	for i, colName := range headerLine {
+		if align, ok := cfg.ColumnAlign[colName]; ok {
+			cfg.columnAligns[i] = align
+		} else {
+			cfg.columnAligns[i] = cfg.Align
+		}
+	}
+
+	return cfg
 }
 
 // Populate orderColumnIndices in Config object
 func populateColumnIndices(cfg Config, headerLine []string) Config {
 	// get the new order of columns after sorted, compared to the original order of them.
-	if cfg.SortColumns == None {
+	switch {
+	case len(cfg.ColumnOrder) > 0:
+		cfg.orderedColumnsIndices = getIndicesOfNamedColumns(cfg.ColumnOrder, headerLine)
+	case cfg.SortColumns == None:
 		for i := range len(headerLine) {
 			cfg.orderedColumnsIndices = append(cfg.orderedColumnsIndices, i)
 		}
-	} else {
+	default:
 		getIndicesAfterSorting(&cfg, headerLine)
 	}
 
+	if len(cfg.PinnedColumns) > 0 {
+		pinned := getIndicesOfNamedColumns(cfg.PinnedColumns, headerLine)
+		rest := slices.DeleteFunc(cfg.orderedColumnsIndices, func(i int) bool {
+			return slices.Contains(pinned, i)
+		})
+		cfg.orderedColumnsIndices = append(pinned, rest...)
+	}
+
 	return cfg
 }
 
-// Get the indices of columns after sorted
+// Get the indices of the columns with the given names, in the order of the names. Every column of a repeated header name is included, each only once
+func getIndicesOfNamedColumns(colNames []string, headerLine []string) []int {
+	var indices []int
+	included := make([]bool, len(headerLine))
+
+	for _, colName := range colNames {
+		for colIdx, headerName := range headerLine {
+			if headerName == colName && !included[colIdx] {
+				included[colIdx] = true
+				indices = append(indices, colIdx)
+			}
+		}
+	}
+
+	return indices
+}
+
+// Get the indices of columns after sorted. Columns that compare equal keep their original order
 func getIndicesAfterSorting(cfg *Config, headerLine []string) {
-	sortedColumns := make([]string, len(headerLine))
-	copy(sortedColumns, headerLine)
+	cfg.orderedColumnsIndices = make([]int, len(headerLine))
+	for i := range headerLine {
+		cfg.orderedColumnsIndices[i] = i
+	}
+
+	compareFunc, _ := comparatorFunction(cfg.SortComparator)
 
 	switch cfg.SortColumns {
 	case Ascending:
-		slices.SortFunc(sortedColumns, func(a, b string) int {
-			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		slices.SortStableFunc(cfg.orderedColumnsIndices, func(a, b int) int {
+			return compareFunc(headerLine[a], headerLine[b])
 		})
 	case Descending:
-		slices.SortFunc(sortedColumns, func(a, b string) int {
-			return strings.Compare(strings.ToLower(b), strings.ToLower(a))
+		slices.SortStableFunc(cfg.orderedColumnsIndices, func(a, b int) int {
+			return compareFunc(headerLine[b], headerLine[a])
 		})
 	case Custom:
-		slices.SortFunc(sortedColumns, cfg.SortFunction)
-	}
-
-	for i := range sortedColumns {
-		cfg.orderedColumnsIndices = append(cfg.orderedColumnsIndices, slices.Index(headerLine, sortedColumns[i]))
+		slices.SortStableFunc(cfg.orderedColumnsIndices, func(a, b int) int {
+			return cfg.SortFunction(headerLine[a], headerLine[b])
+		})
 	}
 }
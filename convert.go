@@ -3,245 +3,435 @@ package mdtable
 import (
 	"errors"
 	"fmt"
-	"log/slog"
+	"io"
 	"slices"
 	"strings"
-	"unicode/utf8"
 )
 
+// Returned when there are no records to convert, not even a header line
+var ErrNoRecords = errors.New("no records to convert")
+
+// Wraps every problem of the config found by Convert, including columns that don't exist in the header line
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Error about a single cell of the records. Row is 0 for the header line and counts the data lines from 1, Col is the index of the column
+type ColumnError struct {
+	Row   int
+	Col   int
+	Value string
+	Err   error
+}
+
+func (e *ColumnError) Error() string {
+	return fmt.Sprintf("row %d col %d: %s", e.Row, e.Col, e.Err)
+}
+
+func (e *ColumnError) Unwrap() error {
+	return e.Err
+}
+
 // Convert string into a markdown table. Returns the string representation of the markdown table if converted successfully and an error if failed.
+// Other output formats can be selected with Config.Renderer. The records are only read, never modified, so the same records
+// can be converted several times and from several goroutines at once. ErrNoRecords is returned if records is empty or its header line has no fields.
 func Convert(records [][]string, cfg Config) (string, error) {
 
-	cfgErr := ValidateConfig(cfg)
+	var result strings.Builder
+
+	if err := render(&result, records, cfg, &Report{}); err != nil {
+		return "", err
+	}
+
+	// renderers terminate every line, but the table itself doesn't end with a new line
+	return strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+// Validate the config against the records and resolve the sorting, exclusion, ordering and alignment of the columns.
+// This is shared by every output format. Warnings are added to the report. Returns whether all columns were excluded, in which case nothing should be rendered.
+func prepareConversion(records [][]string, cfg Config, report *Report) (Config, [][]string, bool, error) {
+
+	cfg = populateDebugLogger(cfg)
+	cfgWarnings, cfgErr := validateConfig(cfg)
 
 	if cfgErr != nil {
-		return "", fmt.Errorf("Configuration error: %s\n", cfgErr)
+		return cfg, nil, false, fmt.Errorf("%w: %w", ErrInvalidConfig, cfgErr)
+	}
+
+	if !cfg.configWarningsLogged {
+		logConfigWarnings(cfg, cfgWarnings)
 	}
+	report.Warnings = append(report.Warnings, cfgWarnings...)
 
-	if cfg.VerboseLogging {
-		slog.SetLogLoggerLevel(slog.LevelDebug)
+	if len(records) == 0 || len(records[0]) == 0 {
+		return cfg, nil, false, ErrNoRecords
 	}
 
-	cfg.excludedColumnsIndices = getIndicesOfExcludedColumns(cfg.ExcludedColumns, records[0])
+	records, err := fitRaggedRows(records, cfg.RaggedRows)
 
-	if len(cfg.excludedColumnsIndices) > 0 && len(cfg.excludedColumnsIndices) == len(records[0]) {
-		slog.Warn("All columns were excluded from conversion. Returning an empty string")
-		return "", nil
+	if err != nil {
+		return cfg, nil, false, err
 	}
 
-	cfg = populateColumnIndices(cfg, records[0])
+	cfgErr = validateColumnNames(cfg, records[0])
 
-	colCount := len(records[0])
-	result := ""
+	if cfgErr != nil {
+		return cfg, nil, false, fmt.Errorf("%w: %w", ErrInvalidConfig, cfgErr)
+	}
 
-	if cfg.Caption != "" {
-		result += fmt.Sprintf("<!-- %s -->\n", cfg.Caption)
+	if len(cfg.SortRows) > 0 {
+		cfg.debug("Sorting data lines", "keys", len(cfg.SortRows), "rows", len(records)-1)
+	}
+	records = sortRows(records, cfg.SortRows)
+
+	var unmatched []ColumnMatcher
+	cfg.excludedColumnsIndices, unmatched = getIndicesOfExcludedColumns(cfg, records[0])
+	cfg.debug("Resolved excluded columns", "excluded", cfg.ExcludedColumns, "indices", cfg.excludedColumnsIndices)
+
+	for _, matcher := range unmatched {
+		report.warn(cfg, Warning{
+			Code:    WarnPatternNotMatched,
+			Message: fmt.Sprintf("Column pattern %s does not match any column of the header line.", matcher),
+			Column:  matcher.Pattern,
+		})
 	}
 
-	// escape all pipe characters
-	for idx := range len(records) {
-		records[idx] = replaceAllInSlice(records[idx], "|", `\|`)
+	for _, colName := range cfg.ExcludedColumns {
+		if !slices.Contains(records[0], colName) {
+			report.warn(cfg, Warning{
+				Code:    WarnExcludedColumnNotFound,
+				Message: fmt.Sprintf("Excluded column %q does not exist in the header line.", colName),
+				Column:  colName,
+			})
+		}
 	}
 
-	// max length of each column so we can beautify the table
-	maxLenOfCol := getMaxColumnLengths(records, cfg.Align)
+	cfg = populateColumnIndices(cfg, records[0])
+	cfg = populateColumnAligns(cfg, records[0])
+	cfg.debug("Resolved column order", "sort", cfg.SortColumns, "order", cfg.ColumnOrder, "pinned", cfg.PinnedColumns, "indices", cfg.orderedColumnsIndices)
+
+	// columns are dropped when excluded or when they are not part of ColumnOrder
+	visibleColumns := getVisibleColumnsIndices(cfg)
+	for colIdx, colName := range records[0] {
+		if !slices.Contains(visibleColumns, colIdx) {
+			report.DroppedColumns = append(report.DroppedColumns, colName)
+		}
+	}
 
-	// constructing each data line
-	for idx := range len(records) {
-		convertedLine, err := constructDataLine(records[idx], cfg, maxLenOfCol, idx)
+	if len(visibleColumns) == 0 {
+		report.warn(cfg, Warning{
+			Code:    WarnAllColumnsExcluded,
+			Message: "All columns were excluded from conversion. Returning an empty string",
+		})
+		return cfg, records, true, nil
+	}
 
-		if err != nil {
-			return "", err
+	return cfg, records, false, nil
+}
+
+// Renderer of GitHub Flavored Markdown pipe tables, either well-formatted or compact
+type markdownRenderer struct{}
+
+// Escape all pipe characters. Backslashes are doubled only when they would otherwise combine with a pipe, i.e. right before a pipe
+// or at the end of the cell, so that other Markdown escapes such as \* are kept as they are
+func (markdownRenderer) Escape(value string) string {
+	var escaped strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '|':
+			escaped.WriteString(`\|`)
+		case '\\':
+			run := value[i : len(value)-len(strings.TrimLeft(value[i:], `\`))]
+			escaped.WriteString(run)
+
+			rest := value[i+len(run):]
+			if strings.HasPrefix(rest, "|") || strings.TrimLeft(rest, " \t") == "" {
+				escaped.WriteString(run)
+			}
+			i += len(run) - 1
+		default:
+			escaped.WriteByte(value[i])
 		}
+	}
+
+	return escaped.String()
+}
 
-		convertedLine = strings.TrimSpace(convertedLine)
+func (markdownRenderer) Header(w io.Writer, layout TableLayout) error {
+	layout = markdownLayout(layout)
 
-		// only attach a new line if it's not the last line in the table
-		if idx < len(records)-1 {
-			convertedLine += "\n"
+	if layout.Caption != "" {
+		if _, err := fmt.Fprintf(w, "<!-- %s -->\n", layout.Caption); err != nil {
+			return err
 		}
+	}
+
+	return writeDataLine(w, layout.Header, layout, 0)
+}
+
+func (markdownRenderer) Separator(w io.Writer, layout TableLayout) error {
+	layout = markdownLayout(layout)
+	_, err := io.WriteString(w, constructSeparatorLine(layout))
+	return err
+}
 
-		// append to result string
-		result += convertedLine
+func (markdownRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	// the header line is the first line of the table
+	return writeDataLine(w, cells, markdownLayout(layout), rowIdx+1)
+}
+
+func (markdownRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (markdownRenderer) adjustLayout(layout TableLayout) TableLayout {
+	return markdownLayout(layout)
+}
+
+// Widen narrow columns so that their separator can hold the alignment colons
+func markdownLayout(layout TableLayout) TableLayout {
+	// most tables don't have narrow columns, no need to copy the widths for every line then
+	if !slices.ContainsFunc(layout.Widths, func(width int) bool { return width < 3 }) {
+		return layout
+	}
+
+	widths := make([]int, len(layout.Widths))
+	copy(widths, layout.Widths)
 
-		// after first line, we shall get a separator line
-		if idx == 0 {
-			separatorLine := constructSeparatorLine(colCount, maxLenOfCol, cfg)
-			result += separatorLine
+	for idx, colLen := range widths {
+		if colLen <= 2 && layout.Align[idx] == Center {
+			// if align is center, we need at least 3 spaces (:-:)
+			widths[idx] = 3
+		} else if colLen < 2 && layout.Align[idx] != Center {
+			widths[idx] = 2
 		}
 	}
 
-	return result, nil
+	layout.Widths = widths
+	return layout
+}
+
+// Construct a data line and write it
+func writeDataLine(w io.Writer, colVals []string, layout TableLayout, currRowIdx int) error {
+	convertedLine, err := constructDataLine(colVals, layout, currRowIdx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, convertedLine)
+	return err
 }
 
 // Construct data line
-func constructDataLine(colVals []string, cfg Config, maxLenOfCol []int, currRowIdx int) (string, error) {
-	if cfg.Compact {
-		return constructCompactDataLine(colVals, cfg)
+func constructDataLine(colVals []string, layout TableLayout, currRowIdx int) (string, error) {
+	if layout.Compact {
+		return constructCompactDataLine(colVals)
 	} else {
-		return constructBeautifulDataLine(colVals, cfg, maxLenOfCol, currRowIdx)
+		return constructBeautifulDataLine(colVals, layout, currRowIdx)
 	}
 }
 
 // Construct a well-formatted data line
-func constructBeautifulDataLine(colVals []string, cfg Config, maxLenOfCol []int, currRowIdx int) (string, error) {
-
-	convertedLine := "| "
-
-	for _, i := range cfg.orderedColumnsIndices {
-		// If current column is excluded, ignore it
-		if slices.Contains(cfg.excludedColumnsIndices, i) {
-			continue
-		}
+func constructBeautifulDataLine(colVals []string, layout TableLayout, currRowIdx int) (string, error) {
 
-		paddedString := ""
-		var err error = nil
+	var convertedLine strings.Builder
+	convertedLine.Grow(lineLength(layout.Widths, 3))
+	convertedLine.WriteString("|")
 
-		switch cfg.Align {
-		case Left:
-			paddedString, err = padEnd(colVals[i], maxLenOfCol[i], ' ')
-		case Right:
-			paddedString, err = padStart(colVals[i], maxLenOfCol[i], ' ')
-		case Center:
-			paddedString, err = padCenter(colVals[i], maxLenOfCol[i], ' ')
-		}
+	for i := range colVals {
+		paddedString, err := padCell(colVals[i], layout.Widths[i], layout.Align[i])
 
 		if err != nil {
-			return "", errors.New("something happened when padding value " + colVals[i] + " row: " + fmt.Sprint(currRowIdx) +
-				" col: " + fmt.Sprint(i) + ". Error message: " + err.Error())
+			return "", &ColumnError{Row: currRowIdx, Col: i, Value: colVals[i], Err: err}
 		}
 
-		convertedLine += paddedString + " | "
+		convertedLine.WriteString(" ")
+		convertedLine.WriteString(paddedString)
+		convertedLine.WriteString(" |")
 	}
 
-	return convertedLine, nil
+	convertedLine.WriteString("\n")
+
+	return convertedLine.String(), nil
 }
 
 // Construct a compact data line
-func constructCompactDataLine(colVals []string, cfg Config) (string, error) {
+func constructCompactDataLine(colVals []string) (string, error) {
 
-	convertedLine := "|"
+	var convertedLine strings.Builder
+	convertedLine.WriteString("|")
 
-	for _, i := range cfg.orderedColumnsIndices {
-		// If current column is excluded, ignore it
-		if slices.Contains(cfg.excludedColumnsIndices, i) {
-			continue
-		}
-		convertedLine += colVals[i] + "|"
+	for i := range colVals {
+		convertedLine.WriteString(colVals[i])
+		convertedLine.WriteString("|")
 	}
 
-	return convertedLine, nil
+	convertedLine.WriteString("\n")
+
+	return convertedLine.String(), nil
 }
 
 // Construct a separator line between the header line and data lines
-func constructSeparatorLine(colsCount int, maxLenOfCol []int, cfg Config) string {
-	if cfg.Compact {
-		// since we're in compact mode, all columns separator will look alike. We just care about the number of columns included
-		return constructCompactSeparatorLine(colsCount-len(cfg.excludedColumnsIndices), cfg.Align)
+func constructSeparatorLine(layout TableLayout) string {
+	if layout.Compact {
+		// since we're in compact mode, column widths don't matter. We just care about the alignment of each included column
+		return constructCompactSeparatorLine(layout)
 	} else {
-		return constructBeautifulSeparatorLine(cfg, maxLenOfCol)
+		return constructBeautifulSeparatorLine(layout)
 	}
 }
 
 // Construct a well-formatted separator line
-func constructBeautifulSeparatorLine(cfg Config, maxLenOfCol []int) string {
+func constructBeautifulSeparatorLine(layout TableLayout) string {
 
-	separatorLine := "| "
+	var separatorLine strings.Builder
+	separatorLine.Grow(lineLength(layout.Widths, 3))
+	separatorLine.WriteString("|")
 
-	for _, i := range cfg.orderedColumnsIndices {
-		// If current column is excluded, ignore it
-		if slices.Contains(cfg.excludedColumnsIndices, i) {
-			continue
-		}
+	for i, width := range layout.Widths {
+		separatorLine.WriteString(" ")
 
-		dashes := ""
-		// loop through max length of each column and add dashes
-		for range maxLenOfCol[i] {
-			dashes += "-"
-		}
-		switch cfg.Align {
+		switch layout.Align[i] {
 		case Left:
-			// replace the first dash with a colon. This makes the rendered table align text on the left hand side
-			dashes = strings.Replace(dashes, "-", ":", 1)
+			// the first dash is a colon. This makes the rendered table align text on the left hand side
+			separatorLine.WriteString(":" + strings.Repeat("-", width-1))
 		case Right:
-			// replace the last dash with a colon. This makes the rendered table align text on the right hand side
-			i := strings.LastIndex(dashes, "-")
-			excludingLast := dashes[:i] + strings.Replace(dashes[i:], "-", "", 1)
-			dashes = excludingLast + ":"
+			// the last dash is a colon. This makes the rendered table align text on the right hand side
+			separatorLine.WriteString(strings.Repeat("-", width-1) + ":")
 		case Center:
-			// replace the first and last dashes with colons
-			// first
-			dashes = strings.Replace(dashes, "-", ":", 1)
-
-			// last
-			i := strings.LastIndex(dashes, "-")
-			excludingLast := dashes[:i] + strings.Replace(dashes[i:], "-", "", 1)
-			dashes = excludingLast + ":"
+			// the first and last dashes are colons
+			separatorLine.WriteString(":" + strings.Repeat("-", width-2) + ":")
 		}
-		separatorLine += dashes + " | "
+
+		separatorLine.WriteString(" |")
 	}
 
-	// trim any potential leading/following whitespaces and add new line character
-	separatorLine = strings.TrimSpace(separatorLine)
-	separatorLine += "\n"
+	separatorLine.WriteString("\n")
 
-	return separatorLine
+	return separatorLine.String()
 }
 
 // Construct a compact separator line
-func constructCompactSeparatorLine(colCount int, align Align) string {
-	separatorLine := "|"
-	for range colCount {
+func constructCompactSeparatorLine(layout TableLayout) string {
+	var separatorLine strings.Builder
+	separatorLine.WriteString("|")
+
+	for _, align := range layout.Align {
 		switch align {
 		case Left:
-			separatorLine += ":-|"
+			separatorLine.WriteString(":-|")
 		case Right:
-			separatorLine += "-:|"
+			separatorLine.WriteString("-:|")
 		case Center:
-			separatorLine += ":-:|"
+			separatorLine.WriteString(":-:|")
 		}
 	}
 
-	// trim any potential leading/following whitespaces and add new line character
-	separatorLine = strings.TrimSpace(separatorLine)
-	separatorLine += "\n"
+	separatorLine.WriteString("\n")
+
+	return separatorLine.String()
+}
+
+// Get the length of a line made of the given column widths, each column adding some decoration around its value
+func lineLength(widths []int, decorationPerColumn int) int {
+	length := 2
+	for _, width := range widths {
+		length += width + decorationPerColumn
+	}
+
+	return length
+}
 
-	return separatorLine
+// Pad a value to the width of its column according to the alignment of the column
+func padCell(value string, width int, align Align) (string, error) {
+	switch align {
+	case Left:
+		return padEnd(value, width, ' ')
+	case Right:
+		return padStart(value, width, ' ')
+	default:
+		return padCenter(value, width, ' ')
+	}
 }
 
-// Get max length of each columns
-func getMaxColumnLengths(lines [][]string, align Align) []int {
+// Get max length of each columns. Columns aligned with Auto are resolved to Left, Right or Center based on their data lines
+func getMaxColumnLengths(lines [][]string, aligns []Align) ([]int, []Align) {
 	maxLens := make([]int, len(lines[0]))
-	for _, fields := range lines {
+	resolvedAligns := make([]Align, len(aligns))
+	copy(resolvedAligns, aligns)
+
+	// a column is numeric/flag-like until one of its non-empty data values proves otherwise
+	allNumeric := make([]bool, len(lines[0]))
+	allFlags := make([]bool, len(lines[0]))
+	hasValues := make([]bool, len(lines[0]))
+	for idx := range allNumeric {
+		allNumeric[idx] = true
+		allFlags[idx] = true
+	}
+
+	for lineIdx, fields := range lines {
 		for fieldIdx, fieldVal := range fields {
-			if utf8.RuneCountInString(fieldVal) > maxLens[fieldIdx] {
-				maxLens[fieldIdx] = utf8.RuneCountInString(fieldVal)
+			maxLens[fieldIdx] = max(maxLens[fieldIdx], displayWidth(fieldVal))
+
+			// header line does not say anything about the content of the column
+			if lineIdx == 0 || aligns[fieldIdx] != Auto || strings.TrimSpace(fieldVal) == "" {
+				continue
 			}
+
+			hasValues[fieldIdx] = true
+			allNumeric[fieldIdx] = allNumeric[fieldIdx] && isNumeric(fieldVal)
+			allFlags[fieldIdx] = allFlags[fieldIdx] && isFlag(fieldVal)
 		}
 	}
 
-	for idx, colLen := range maxLens {
-		if colLen <= 2 && align == Center {
-			// if align is center, we need at least 3 spaces (:-:)
-			maxLens[idx] = 3
-		} else if colLen < 2 && align != Center {
-			maxLens[idx] = 2
+	for idx, align := range resolvedAligns {
+		if align != Auto {
+			continue
+		}
+
+		switch {
+		case hasValues[idx] && allNumeric[idx]:
+			resolvedAligns[idx] = Right
+		case hasValues[idx] && allFlags[idx]:
+			resolvedAligns[idx] = Center
+		default:
+			resolvedAligns[idx] = Left
 		}
 	}
 
-	return maxLens
+	return maxLens, resolvedAligns
 }
 
-// Get the indices of columns that are excluded in config
-func getIndicesOfExcludedColumns(excludedColumns []string, headerLine []string) []int {
-	var excludedColumnsIndices []int
-	if len(excludedColumns) > 0 {
+// Get the indices of columns that are excluded in config, by name, by ExcludeMatchers or by not matching IncludeMatchers.
+// Also returns the matchers that didn't match any column
+func getIndicesOfExcludedColumns(cfg Config, headerLine []string) ([]int, []ColumnMatcher) {
+	excluded, unmatched := matchColumns(cfg.ExcludeMatchers, headerLine)
+
+	if len(cfg.IncludeMatchers) > 0 {
+		included, unmatchedIncludes := matchColumns(cfg.IncludeMatchers, headerLine)
+		unmatched = append(unmatched, unmatchedIncludes...)
+
+		for colIdx := range excluded {
+			excluded[colIdx] = excluded[colIdx] || !included[colIdx]
+		}
+	}
+
+	if len(cfg.ExcludedColumns) > 0 {
+		excludedNames := make(map[string]bool, len(cfg.ExcludedColumns))
+		for _, colName := range cfg.ExcludedColumns {
+			excludedNames[colName] = true
+		}
+
 		for colIdx := range len(headerLine) {
-			// if column is found and not duplicated
-			if slices.Contains(excludedColumns, headerLine[colIdx]) {
-				excludedColumnsIndices = append(excludedColumnsIndices, colIdx)
-			}
+			excluded[colIdx] = excluded[colIdx] || excludedNames[headerLine[colIdx]]
+		}
+	}
+
+	var excludedColumnsIndices []int
+	for colIdx := range excluded {
+		if excluded[colIdx] {
+			excludedColumnsIndices = append(excludedColumnsIndices, colIdx)
 		}
 	}
-	return excludedColumnsIndices
-}
\ No newline at end of file
+	return excludedColumnsIndices, unmatched
+}
@@ -0,0 +1,95 @@
+package mdtable
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// UTF-8 byte order mark, commonly written at the start of CSV files exported by spreadsheet applications
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Options of the CSV dialect read by ReadCSV
+type CSVOptions struct {
+	// Field delimiter such as ',', '\t', ';' or '|'. Comma is used if not set
+	Delimiter rune
+
+	// Lines starting with this character are ignored. Comment lines are not recognized if not set
+	Comment rune
+
+	// Allow quotes in unquoted fields and unescaped quotes in quoted fields
+	LazyQuotes bool
+
+	// Keep a leading UTF-8 byte order mark as part of the first field instead of stripping it
+	KeepBOM bool
+
+	// The input has no header line. Columns are named "Column 1", "Column 2", ... instead
+	NoHeader bool
+
+	// Trim the whitespace surrounding every field
+	TrimSpace bool
+
+	// Allow lines with a different number of fields than the first one. Convert handles them according to Config.RaggedRows
+	AllowRagged bool
+}
+
+// Read CSV records that can be passed to Convert. Every line must have the same number of fields as the first one, unless AllowRagged is set.
+func ReadCSV(r io.Reader, opts CSVOptions) ([][]string, error) {
+	if !opts.KeepBOM {
+		r = stripBOM(r)
+	}
+
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.Comment = opts.Comment
+	reader.LazyQuotes = opts.LazyQuotes
+	reader.TrimLeadingSpace = opts.TrimSpace
+	if opts.AllowRagged {
+		reader.FieldsPerRecord = -1
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	if opts.TrimSpace {
+		for _, record := range records {
+			for idx := range record {
+				record[idx] = strings.TrimSpace(record[idx])
+			}
+		}
+	}
+
+	if opts.NoHeader && len(records) > 0 {
+		header := make([]string, len(records[0]))
+		for idx := range header {
+			header[idx] = fmt.Sprintf("Column %d", idx+1)
+		}
+		records = append([][]string{header}, records...)
+	}
+
+	return records, nil
+}
+
+// Read tab-separated records that can be passed to Convert. Quotes are read literally unless they surround a whole field.
+func ReadTSV(r io.Reader, opts CSVOptions) ([][]string, error) {
+	opts.Delimiter = '\t'
+	opts.LazyQuotes = true
+	return ReadCSV(r, opts)
+}
+
+// Wrap the reader so that a leading UTF-8 byte order mark is skipped
+func stripBOM(r io.Reader) io.Reader {
+	bufReader := bufio.NewReader(r)
+	if prefix, err := bufReader.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		bufReader.Discard(len(utf8BOM))
+	}
+
+	return bufReader
+}
@@ -0,0 +1,471 @@
+package mdtable
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var asciiDocColumnAlign = map[Align]string{
+	Center: "^",
+	Left:   "<",
+	Right:  ">",
+}
+
+var latexColumnAlign = map[Align]string{
+	Center: "c",
+	Left:   "l",
+	Right:  "r",
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// Renderer of reStructuredText grid tables. The caption becomes a table directive. Cells are always padded
+type rstGridRenderer struct{}
+
+func (rstGridRenderer) Escape(value string) string {
+	return value
+}
+
+func (rstGridRenderer) Header(w io.Writer, layout TableLayout) error {
+	header, err := padCells(layout.Header, layout, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := writeRSTDirective(w, layout); err != nil {
+		return err
+	}
+
+	return writeLines(w, rstIndent(layout),
+		rstGridBorder(layout, '-'),
+		"| "+strings.Join(header, " | ")+" |",
+	)
+}
+
+func (rstGridRenderer) Separator(w io.Writer, layout TableLayout) error {
+	// a grid table can't end with the header separator
+	if layout.Rows == 0 {
+		return writeLines(w, rstIndent(layout), rstGridBorder(layout, '-'))
+	}
+
+	return writeLines(w, rstIndent(layout), rstGridBorder(layout, '='))
+}
+
+func (rstGridRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	cells, err := padCells(cells, layout, rowIdx+1)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, rstIndent(layout), "| "+strings.Join(cells, " | ")+" |", rstGridBorder(layout, '-'))
+}
+
+func (rstGridRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+// Renderer of reStructuredText simple tables. The caption becomes a table directive. Cells are always padded
+type rstSimpleRenderer struct{}
+
+func (rstSimpleRenderer) Escape(value string) string {
+	return value
+}
+
+func (rstSimpleRenderer) Header(w io.Writer, layout TableLayout) error {
+	layout = rstSimpleLayout(layout)
+
+	header, err := padCells(rstSimpleCells(layout.Header), layout, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := writeRSTDirective(w, layout); err != nil {
+		return err
+	}
+
+	return writeLines(w, rstIndent(layout),
+		rstSimpleBorder(layout),
+		strings.TrimRight(strings.Join(header, "  "), " "),
+	)
+}
+
+func (rstSimpleRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return writeLines(w, rstIndent(layout), rstSimpleBorder(rstSimpleLayout(layout)))
+}
+
+func (rstSimpleRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	layout = rstSimpleLayout(layout)
+
+	cells, err := padCells(rstSimpleCells(cells), layout, rowIdx+1)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, rstIndent(layout), strings.TrimRight(strings.Join(cells, "  "), " "))
+}
+
+func (rstSimpleRenderer) Footer(w io.Writer, layout TableLayout) error {
+	// without data lines the header separator already closes the table
+	if layout.Rows == 0 {
+		return nil
+	}
+
+	return writeLines(w, rstIndent(layout), rstSimpleBorder(rstSimpleLayout(layout)))
+}
+
+func (rstSimpleRenderer) adjustLayout(layout TableLayout) TableLayout {
+	return rstSimpleLayout(layout)
+}
+
+// Widen columns so that every border has at least one "=" and the first column can hold the ".." of an empty cell
+func rstSimpleLayout(layout TableLayout) TableLayout {
+	// called for every line, only copy the widths when a column is empty or the first one can't hold ".."
+	if len(layout.Widths) == 0 || (layout.Widths[0] >= 2 && !slices.Contains(layout.Widths, 0)) {
+		return layout
+	}
+
+	widths := make([]int, len(layout.Widths))
+	for i, width := range layout.Widths {
+		widths[i] = max(width, 1)
+	}
+	widths[0] = max(widths[0], 2)
+
+	layout.Widths = widths
+	return layout
+}
+
+// Replace an empty first cell with an empty comment. A line starting with spaces would continue the previous line instead
+func rstSimpleCells(cells []string) []string {
+	if len(cells) == 0 || strings.TrimSpace(cells[0]) != "" {
+		return cells
+	}
+
+	cells = slices.Clone(cells)
+	cells[0] = ".."
+	return cells
+}
+
+// Renderer of AsciiDoc tables with the alignment of each column in the cols attribute
+type asciiDocRenderer struct{}
+
+func (asciiDocRenderer) Escape(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+func (asciiDocRenderer) Header(w io.Writer, layout TableLayout) error {
+	cols := make([]string, len(layout.Align))
+	for i, align := range layout.Align {
+		cols[i] = asciiDocColumnAlign[align]
+	}
+
+	lines := []string{}
+	if layout.Caption != "" {
+		lines = append(lines, "."+layout.Caption)
+	}
+	lines = append(lines, fmt.Sprintf(`[cols="%s",options="header"]`, strings.Join(cols, ",")), "|===")
+
+	if err := writeLines(w, "", lines...); err != nil {
+		return err
+	}
+
+	return asciiDocRenderer{}.Row(w, layout, layout.Header, -1)
+}
+
+func (asciiDocRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (asciiDocRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	if layout.Compact {
+		return writeLines(w, "", "|"+strings.Join(cells, "|"))
+	}
+
+	cells, err := padCells(cells, layout, rowIdx+1)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, "", strings.TrimRight("| "+strings.Join(cells, " | "), " "))
+}
+
+func (asciiDocRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return writeLines(w, "", "|===")
+}
+
+// Renderer of Org-mode tables
+type orgRenderer struct{}
+
+func (orgRenderer) Escape(value string) string {
+	return strings.ReplaceAll(value, "|", `\vert{}`)
+}
+
+func (orgRenderer) Header(w io.Writer, layout TableLayout) error {
+	if layout.Caption != "" {
+		if err := writeLines(w, "", "#+CAPTION: "+layout.Caption); err != nil {
+			return err
+		}
+	}
+
+	return orgRenderer{}.Row(w, layout, layout.Header, -1)
+}
+
+func (orgRenderer) Separator(w io.Writer, layout TableLayout) error {
+	if layout.Compact {
+		return writeLines(w, "", "|-"+strings.Repeat("+-", len(layout.Widths)-1)+"|")
+	}
+
+	dashes := make([]string, len(layout.Widths))
+	for i, width := range layout.Widths {
+		dashes[i] = strings.Repeat("-", width+2)
+	}
+
+	return writeLines(w, "", "|"+strings.Join(dashes, "+")+"|")
+}
+
+func (orgRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	if layout.Compact {
+		return writeLines(w, "", "|"+strings.Join(cells, "|")+"|")
+	}
+
+	cells, err := padCells(cells, layout, rowIdx+1)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, "", "| "+strings.Join(cells, " | ")+" |")
+}
+
+func (orgRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+// Renderer of MediaWiki tables with the alignment of every cell in its style attribute
+type mediaWikiRenderer struct{}
+
+func (mediaWikiRenderer) Escape(value string) string {
+	return strings.ReplaceAll(value, "|", "&#124;")
+}
+
+func (mediaWikiRenderer) Header(w io.Writer, layout TableLayout) error {
+	lines := []string{`{| class="wikitable"`}
+	if layout.Caption != "" {
+		lines = append(lines, "|+ "+layout.Caption)
+	}
+
+	if err := writeLines(w, "", lines...); err != nil {
+		return err
+	}
+
+	return writeMediaWikiCells(w, layout, layout.Header, "!")
+}
+
+func (mediaWikiRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (mediaWikiRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	if err := writeLines(w, "", "|-"); err != nil {
+		return err
+	}
+
+	return writeMediaWikiCells(w, layout, cells, "|")
+}
+
+func (mediaWikiRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return writeLines(w, "", "|}")
+}
+
+// Write the cells of a MediaWiki row, one per line or all on one line if the table is compact
+func writeMediaWikiCells(w io.Writer, layout TableLayout, cells []string, marker string) error {
+	lines := make([]string, len(cells))
+	for i, cell := range cells {
+		lines[i] = fmt.Sprintf(`style="text-align:%s" | %s`, htmlTextAlign[layout.Align[i]], cell)
+	}
+
+	if layout.Compact {
+		return writeLines(w, "", strings.TrimRight(marker+" "+strings.Join(lines, " "+marker+marker+" "), " "))
+	}
+
+	for i := range lines {
+		lines[i] = strings.TrimRight(marker+" "+lines[i], " ")
+	}
+
+	return writeLines(w, "", lines...)
+}
+
+// Renderer of Jira and Confluence wiki markup tables. The caption is written in bold above the table
+type jiraRenderer struct{}
+
+// Escape pipe characters. Empty cells become a space since || starts a header cell
+func (jiraRenderer) Escape(value string) string {
+	if value == "" {
+		return " "
+	}
+
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+func (jiraRenderer) Header(w io.Writer, layout TableLayout) error {
+	if layout.Caption != "" {
+		if err := writeLines(w, "", "*"+layout.Caption+"*"); err != nil {
+			return err
+		}
+	}
+
+	return writeJiraCells(w, layout, layout.Header, "||", 0)
+}
+
+func (jiraRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (jiraRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	return writeJiraCells(w, layout, cells, "|", rowIdx+1)
+}
+
+func (jiraRenderer) Footer(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+// Write a Jira row, padded unless the table is compact. lineIdx is 0 for the header line and counts the data lines from 1
+func writeJiraCells(w io.Writer, layout TableLayout, cells []string, delimiter string, lineIdx int) error {
+	if layout.Compact {
+		return writeLines(w, "", delimiter+strings.Join(cells, delimiter)+delimiter)
+	}
+
+	cells, err := padCells(cells, layout, lineIdx)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, "", delimiter+" "+strings.Join(cells, " "+delimiter+" ")+" "+delimiter)
+}
+
+// Renderer of LaTeX tabular environments. With a caption, the tabular is wrapped in a table environment
+type latexRenderer struct{}
+
+func (latexRenderer) Escape(value string) string {
+	return latexEscaper.Replace(value)
+}
+
+func (latexRenderer) Header(w io.Writer, layout TableLayout) error {
+	cols := ""
+	for _, align := range layout.Align {
+		cols += latexColumnAlign[align]
+	}
+
+	lines := []string{}
+	if layout.Caption != "" {
+		lines = append(lines, `\begin{table}`, `\centering`, `\caption{`+latexEscaper.Replace(layout.Caption)+`}`)
+	}
+	lines = append(lines, `\begin{tabular}{`+cols+`}`, `\hline`)
+
+	if err := writeLines(w, "", lines...); err != nil {
+		return err
+	}
+
+	return latexRenderer{}.Row(w, layout, layout.Header, -1)
+}
+
+func (latexRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return writeLines(w, "", `\hline`)
+}
+
+func (latexRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	if !layout.Compact {
+		var err error
+		if cells, err = padCells(cells, layout, rowIdx+1); err != nil {
+			return err
+		}
+	}
+
+	return writeLines(w, "", strings.Join(cells, " & ")+` \\`)
+}
+
+func (latexRenderer) Footer(w io.Writer, layout TableLayout) error {
+	lines := []string{`\hline`, `\end{tabular}`}
+	if layout.Caption != "" {
+		lines = append(lines, `\end{table}`)
+	}
+
+	return writeLines(w, "", lines...)
+}
+
+// Pad every cell to the width and alignment of its column. lineIdx is 0 for the header line and counts the data lines from 1
+func padCells(cells []string, layout TableLayout, lineIdx int) ([]string, error) {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		var err error
+		if padded[i], err = padCell(cell, layout.Widths[i], layout.Align[i]); err != nil {
+			return nil, &ColumnError{Row: lineIdx, Col: i, Value: cell, Err: err}
+		}
+	}
+
+	return padded, nil
+}
+
+// Write the lines with the given indentation, each terminated by a new line. Empty lines are not indented
+func writeLines(w io.Writer, indent string, lines ...string) error {
+	var result strings.Builder
+	for _, line := range lines {
+		if line != "" {
+			result.WriteString(indent + line)
+		}
+		result.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+// Get the indentation of reStructuredText table lines, which are nested in a table directive when there is a caption
+func rstIndent(layout TableLayout) string {
+	if layout.Caption != "" {
+		return "   "
+	}
+
+	return ""
+}
+
+// Write the table directive holding the caption followed by an empty line, or nothing if there is no caption
+func writeRSTDirective(w io.Writer, layout TableLayout) error {
+	if layout.Caption == "" {
+		return nil
+	}
+
+	return writeLines(w, "", ".. table:: "+layout.Caption, "")
+}
+
+// Get a border line of a grid table drawn with the given character
+func rstGridBorder(layout TableLayout, char rune) string {
+	var border strings.Builder
+	border.WriteString("+")
+	for _, width := range layout.Widths {
+		border.WriteString(strings.Repeat(string(char), width+2) + "+")
+	}
+
+	return border.String()
+}
+
+// Get a border line of a simple table
+func rstSimpleBorder(layout TableLayout) string {
+	borders := make([]string, len(layout.Widths))
+	for i, width := range layout.Widths {
+		borders[i] = strings.Repeat("=", width)
+	}
+
+	return strings.Join(borders, "  ")
+}
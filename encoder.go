@@ -0,0 +1,234 @@
+package mdtable
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+// Writes a table to an io.Writer line by line. By default rows are buffered and the table is rendered on Flush, since column widths depend on every row.
+// When column widths are declared with SetFixedWidth or SetColumnWidths, every row is written as soon as it's received and nothing is buffered.
+//
+// Unlike Convert, the output of an Encoder ends with a new line.
+type Encoder struct {
+	w   io.Writer
+	cfg Config
+
+	// declared widths, streaming is enabled when any of them is set
+	fixedWidth   int
+	columnWidths map[string]int
+
+	header  []string
+	records [][]string
+
+	// state of a streamed table
+	renderer       Renderer
+	layout         TableLayout
+	visibleColumns []int
+	allExcluded    bool
+
+	// number of data lines received so far
+	rows int
+
+	err error
+}
+
+// Create an Encoder writing the table described by the config to w
+func NewEncoder(w io.Writer, cfg Config) *Encoder {
+	return &Encoder{w: w, cfg: cfg}
+}
+
+// Stream rows with every column padded to at least the given width. Must be called before WriteHeader
+func (enc *Encoder) SetFixedWidth(width int) {
+	enc.fixedWidth = width
+}
+
+// Stream rows with the given columns padded to at least the given widths, keyed by header name. Other columns are as wide as their header,
+// or as the fixed width if one is set. Must be called before WriteHeader
+func (enc *Encoder) SetColumnWidths(widths map[string]int) {
+	enc.columnWidths = widths
+}
+
+// Write the header line of the table. When streaming, columns aligned with Auto are left aligned since their data is not known yet
+// and SortRows is not supported.
+func (enc *Encoder) WriteHeader(header []string) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if enc.header != nil {
+		return enc.fail(errors.New("header was already written"))
+	}
+
+	enc.header = slices.Clone(header)
+
+	if !enc.streaming() {
+		enc.records = append(enc.records, enc.header)
+		return nil
+	}
+
+	if len(enc.cfg.SortRows) > 0 {
+		return enc.fail(errors.New("SortRows can't be used when streaming rows with declared column widths"))
+	}
+
+	cfg, _, allExcluded, err := prepareConversion([][]string{enc.header}, enc.cfg, &Report{})
+	if err != nil {
+		return enc.fail(err)
+	}
+
+	enc.allExcluded = allExcluded
+	if allExcluded {
+		return nil
+	}
+
+	enc.renderer, _ = lookupRenderer(cfg.Renderer)
+	enc.visibleColumns = getVisibleColumnsIndices(cfg)
+	enc.layout = newTableLayout(cfg, escapeLine(enc.renderer, enc.header, enc.visibleColumns))
+	enc.layout.Widths = make([]int, len(enc.visibleColumns))
+
+	for colIdx, i := range enc.visibleColumns {
+		width, ok := enc.columnWidths[enc.header[i]]
+		if !ok {
+			width = enc.fixedWidth
+		}
+		enc.layout.Widths[colIdx] = max(width, displayWidth(enc.layout.Header[colIdx]))
+
+		if enc.layout.Align[colIdx] == Auto {
+			enc.layout.Align[colIdx] = Left
+		}
+	}
+
+	return enc.fail(enc.renderer.Header(enc.w, enc.layout))
+}
+
+// Write a data line of the table. Data lines with more or fewer fields than the header line are handled according to Config.RaggedRows.
+// When streaming, the header line was already written and can't be extended, so long data lines are an error unless RaggedTruncate is set.
+func (enc *Encoder) WriteRow(row []string) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if enc.header == nil {
+		return enc.fail(errors.New("header must be written before rows"))
+	}
+
+	rowIdx := enc.rows
+
+	if len(row) != len(enc.header) {
+		// once written, the header line can't be extended anymore
+		policy := enc.cfg.RaggedRows
+		if policy == RaggedExtendHeader && enc.streaming() {
+			policy = RaggedPad
+		}
+
+		// the header line is row 0
+		if err := checkRaggedRow(row, len(enc.header), rowIdx+1, policy); err != nil {
+			return enc.fail(err)
+		}
+	}
+
+	enc.rows++
+
+	// buffered rows are fitted when the table is rendered, the header line may still be extended then
+	if !enc.streaming() {
+		enc.records = append(enc.records, slices.Clone(row))
+		return nil
+	}
+
+	if enc.allExcluded {
+		return nil
+	}
+
+	// the separator is written with the first row so that renderers know whether the table has data lines
+	if rowIdx == 0 {
+		enc.layout.Rows = 1
+		if err := enc.renderer.Separator(enc.w, enc.layout); err != nil {
+			return enc.fail(err)
+		}
+	}
+
+	line := escapeLine(enc.renderer, fitRow(row, len(enc.header)), enc.visibleColumns)
+
+	// values wider than their declared width overflow their column instead of failing the whole export
+	layout := enc.layout
+	layout.Widths = slices.Clone(enc.layout.Widths)
+	for colIdx, value := range line {
+		layout.Widths[colIdx] = max(layout.Widths[colIdx], displayWidth(value))
+	}
+
+	return enc.fail(enc.renderer.Row(enc.w, layout, line, rowIdx))
+}
+
+// Finish the table. Buffered rows are rendered and written, streamed tables get their footer.
+// If the writer has a Flush method, like bufio.Writer, it is flushed as well. Nothing can be written after Flush.
+func (enc *Encoder) Flush() error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if enc.header == nil {
+		return enc.fail(errors.New("header must be written before flushing"))
+	}
+
+	var err error
+	switch {
+	case !enc.streaming():
+		err = render(enc.w, enc.records, enc.cfg, &Report{})
+		enc.records = nil
+	case !enc.allExcluded:
+		enc.layout.Rows = enc.rows
+		if enc.rows == 0 {
+			err = enc.writePlaceholder()
+		}
+		if err == nil {
+			err = enc.renderer.Footer(enc.w, enc.layout)
+		}
+	}
+
+	if flusher, ok := enc.w.(interface{ Flush() error }); ok && err == nil {
+		err = flusher.Flush()
+	}
+
+	if err != nil {
+		return enc.fail(err)
+	}
+
+	// any further write is a mistake
+	enc.err = errors.New("encoder was already flushed")
+	return nil
+}
+
+// Write the separator of a streamed table without data lines, followed by the placeholder line if one is configured
+func (enc *Encoder) writePlaceholder() error {
+	if enc.cfg.EmptyPlaceholder != "" {
+		enc.layout.Rows = 1
+		enc.layout.Placeholder = true
+	}
+
+	if err := enc.renderer.Separator(enc.w, enc.layout); err != nil || !enc.layout.Placeholder {
+		return err
+	}
+
+	line := placeholderLine(enc.renderer, enc.cfg.EmptyPlaceholder, len(enc.visibleColumns))
+
+	// the placeholder overflows its column like any other value wider than its declared width
+	layout := enc.layout
+	layout.Widths = slices.Clone(enc.layout.Widths)
+	layout.Widths[0] = max(layout.Widths[0], displayWidth(line[0]))
+
+	return enc.renderer.Row(enc.w, layout, line, 0)
+}
+
+// Whether rows are written as they are received
+func (enc *Encoder) streaming() bool {
+	return enc.fixedWidth > 0 || len(enc.columnWidths) > 0
+}
+
+// Remember the first error so that every following call returns it
+func (enc *Encoder) fail(err error) error {
+	if err != nil && enc.err == nil {
+		enc.err = err
+	}
+
+	return err
+}
@@ -0,0 +1,144 @@
+package mdtable
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Find every table in a Markdown document and render it again with the given Config. Tables inside fenced code blocks are skipped
+// and all other text is left untouched. The alignment written in each table is kept unless Config.ColumnAlign overrides it.
+// Column specific settings such as ColumnAlign, SortRows and ColumnOrder only apply to tables that contain those columns. Caption and Renderer are ignored.
+// Missing cells are rendered empty. Data lines with more cells than the header line are handled according to Config.RaggedRows, so an error is returned by default.
+func Format(document string, cfg Config) (string, error) {
+	cfg = populateDebugLogger(cfg)
+	cfgWarnings, cfgErr := validateConfig(cfg)
+
+	if cfgErr != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidConfig, cfgErr)
+	}
+
+	// log the warnings once for the whole document rather than for every table
+	logConfigWarnings(cfg, cfgWarnings)
+	cfg.configWarningsLogged = true
+
+	lines := strings.Split(document, "\n")
+	formattedLines := make([]string, 0, len(lines))
+
+	// fence of the code block we're currently in, empty when outside of code blocks
+	openFence := ""
+
+	for idx := 0; idx < len(lines); {
+		if openFence == "" {
+			openFence = codeFence(lines[idx])
+		} else if closesCodeFence(openFence, lines[idx]) {
+			openFence = ""
+		}
+
+		if openFence != "" {
+			formattedLines = append(formattedLines, lines[idx])
+			idx++
+			continue
+		}
+
+		table, tableLen := parseTableAt(lines, idx)
+		if tableLen == 0 {
+			formattedLines = append(formattedLines, lines[idx])
+			idx++
+			continue
+		}
+
+		rendered, err := Convert(table.Records, tableConfig(cfg, table))
+		if err != nil {
+			return "", fmt.Errorf("failed to format table at line %d: %w", idx+1, err)
+		}
+
+		if rendered == "" {
+			// every column was excluded, keep the table as it was
+			formattedLines = append(formattedLines, lines[idx:idx+tableLen]...)
+			idx += tableLen
+			continue
+		}
+
+		// keep the indentation and line endings of the original table, e.g. for tables nested in lists
+		indent := lines[idx][:len(lines[idx])-len(strings.TrimLeft(lines[idx], " \t"))]
+		lineEnding := ""
+		if strings.HasSuffix(lines[idx+tableLen-1], "\r") {
+			lineEnding = "\r"
+		}
+
+		for _, renderedLine := range strings.Split(rendered, "\n") {
+			formattedLines = append(formattedLines, indent+renderedLine+lineEnding)
+		}
+
+		idx += tableLen
+	}
+
+	return strings.Join(formattedLines, "\n"), nil
+}
+
+// Get the Config used to render a single table of a document
+func tableConfig(cfg Config, table ParsedTable) Config {
+	header := table.Records[0]
+
+	cfg.Caption = ""
+	cfg.Renderer = DefaultRenderer
+
+	columnAlign := table.ColumnAlign()
+	for colName, align := range cfg.ColumnAlign {
+		if slices.Contains(header, colName) {
+			columnAlign[colName] = align
+		}
+	}
+	cfg.ColumnAlign = columnAlign
+
+	cfg.SortRows = slices.DeleteFunc(slices.Clone(cfg.SortRows), func(key RowSortKey) bool {
+		return !slices.Contains(header, key.Column)
+	})
+
+	cfg.ColumnOrder = slices.DeleteFunc(slices.Clone(cfg.ColumnOrder), func(colName string) bool {
+		return !slices.Contains(header, colName)
+	})
+
+	cfg.PinnedColumns = slices.DeleteFunc(slices.Clone(cfg.PinnedColumns), func(colName string) bool {
+		return !slices.Contains(header, colName)
+	})
+
+	// documents usually hold tables with different columns, an excluded column or pattern missing from one of them is not worth a warning
+	cfg.ExcludedColumns = slices.DeleteFunc(slices.Clone(cfg.ExcludedColumns), func(colName string) bool {
+		return !slices.Contains(header, colName)
+	})
+
+	_, unmatched := matchColumns(slices.Concat(cfg.ExcludeMatchers, cfg.IncludeMatchers), header)
+	isUnmatched := func(matcher ColumnMatcher) bool {
+		return slices.Contains(unmatched, matcher)
+	}
+	cfg.ExcludeMatchers = slices.DeleteFunc(slices.Clone(cfg.ExcludeMatchers), isUnmatched)
+	cfg.IncludeMatchers = slices.DeleteFunc(slices.Clone(cfg.IncludeMatchers), isUnmatched)
+
+	return cfg
+}
+
+// Check whether a line closes the code block opened by openFence: a fence of the same character, at least as long and
+// followed by nothing but whitespace. A line such as ```go inside a ``` block is content
+func closesCodeFence(openFence string, line string) bool {
+	fence := codeFence(line)
+	return fence != "" && fence[0] == openFence[0] && len(fence) >= len(openFence) && strings.TrimSpace(line) == fence
+}
+
+// Get the fence of a line opening or closing a fenced code block, e.g. ``` or ~~~~. Returns an empty string for other lines
+func codeFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+
+	for _, fenceChar := range []string{"`", "~"} {
+		fenceLen := len(trimmed) - len(strings.TrimLeft(trimmed, fenceChar))
+		if fenceLen >= 3 {
+			return strings.Repeat(fenceChar, fenceLen)
+		}
+	}
+
+	return ""
+}
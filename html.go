@@ -0,0 +1,100 @@
+package mdtable
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+var htmlTextAlign = map[Align]string{
+	Center: "center",
+	Left:   "left",
+	Right:  "right",
+}
+
+// Convert records into an HTML table. Columns are excluded, ordered and aligned the same way as in Convert, the caption becomes a <caption> element
+// and cell contents are HTML escaped. Returns the string representation of the HTML table if converted successfully and an error if failed.
+// With Config.Compact the table is written on a single line without indentation.
+func ConvertHTML(records [][]string, cfg Config) (string, error) {
+	cfg.Renderer = "html"
+	return Convert(records, cfg)
+}
+
+// Renderer of HTML tables with a <thead> and a <tbody>
+type htmlRenderer struct{}
+
+func (htmlRenderer) Escape(value string) string {
+	return html.EscapeString(value)
+}
+
+func (htmlRenderer) Header(w io.Writer, layout TableLayout) error {
+	var result strings.Builder
+
+	writeHTMLElement(&result, layout, 0, "<table>")
+
+	if layout.Caption != "" {
+		writeHTMLElement(&result, layout, 1, "<caption>"+html.EscapeString(layout.Caption)+"</caption>")
+	}
+
+	writeHTMLElement(&result, layout, 1, "<thead>")
+	writeHTMLRow(&result, layout, "th", layout.Header)
+	writeHTMLElement(&result, layout, 1, "</thead>")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+func (htmlRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (htmlRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	var result strings.Builder
+
+	if rowIdx == 0 {
+		writeHTMLElement(&result, layout, 1, "<tbody>")
+	}
+	writeHTMLRow(&result, layout, "td", cells)
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+func (htmlRenderer) Footer(w io.Writer, layout TableLayout) error {
+	var result strings.Builder
+
+	if layout.Rows > 0 {
+		writeHTMLElement(&result, layout, 1, "</tbody>")
+	}
+	writeHTMLElement(&result, layout, 0, "</table>")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+// Write a table row with one cell element per column
+func writeHTMLRow(result *strings.Builder, layout TableLayout, cellTag string, cells []string) {
+	writeHTMLElement(result, layout, 2, "<tr>")
+
+	// the placeholder of a header-only table spans every column
+	if layout.Placeholder && cellTag == "td" {
+		writeHTMLElement(result, layout, 3, fmt.Sprintf(`<td colspan="%d" style="text-align:%s">%s</td>`, len(cells), htmlTextAlign[layout.Align[0]], cells[0]))
+	} else {
+		for i, cell := range cells {
+			writeHTMLElement(result, layout, 3, fmt.Sprintf(`<%s style="text-align:%s">%s</%s>`, cellTag, htmlTextAlign[layout.Align[i]], cell, cellTag))
+		}
+	}
+
+	writeHTMLElement(result, layout, 2, "</tr>")
+}
+
+// Write one element on its own line, indented by its depth, unless the table is compact
+func writeHTMLElement(result *strings.Builder, layout TableLayout, depth int, element string) {
+	if layout.Compact {
+		result.WriteString(element)
+		return
+	}
+
+	result.WriteString(strings.Repeat("  ", depth) + element + "\n")
+}
@@ -0,0 +1,173 @@
+package mdtable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Records collected from JSON objects. Columns are kept in the order their keys were first seen
+type jsonRecords struct {
+	header  []string
+	columns map[string]int
+	lines   []map[string]string
+}
+
+// Read a JSON array of objects into records that can be passed to Convert. The header is the union of the keys of all objects in first-seen order,
+// nested objects are flattened into dotted column names such as owner.email, arrays are kept as JSON and null, empty objects or missing keys are empty cells.
+func ReadJSON(r io.Reader) ([][]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	if err := expectDelim(decoder, '['); err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	records := newJSONRecords()
+	for idx := 0; decoder.More(); idx++ {
+		if err := records.readObject(decoder); err != nil {
+			return nil, fmt.Errorf("failed to read JSON element %d: %w", idx, err)
+		}
+	}
+
+	if err := expectDelim(decoder, ']'); err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	return records.toRecords()
+}
+
+// Read JSON Lines, one object per line, into records that can be passed to Convert. Objects are flattened the same way as in ReadJSON.
+func ReadJSONLines(r io.Reader) ([][]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	records := newJSONRecords()
+	for idx := 0; ; idx++ {
+		err := records.readObject(decoder)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON Lines object %d: %w", idx, err)
+		}
+	}
+
+	return records.toRecords()
+}
+
+func newJSONRecords() *jsonRecords {
+	return &jsonRecords{columns: map[string]int{}}
+}
+
+// Read the next object from the decoder as a new line
+func (records *jsonRecords) readObject(decoder *json.Decoder) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	line := map[string]string{}
+	if err := records.readFields(decoder, "", line); err != nil {
+		return err
+	}
+	records.lines = append(records.lines, line)
+
+	return nil
+}
+
+// Read the fields of an object whose opening brace was already read, up to and including its closing brace
+func (records *jsonRecords) readFields(decoder *json.Decoder, prefix string, line map[string]string) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		if value[0] == '{' {
+			nestedDecoder := json.NewDecoder(bytes.NewReader(value))
+			nestedDecoder.UseNumber()
+			nestedDecoder.Token()
+
+			// an object without fields is an empty cell, so that its key still has a column
+			if nestedDecoder.More() {
+				if err := records.readFields(nestedDecoder, key+".", line); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if _, ok := records.columns[key]; !ok {
+			records.columns[key] = len(records.header)
+			records.header = append(records.header, key)
+		}
+
+		if line[key], err = formatJSONValue(value); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+// Turn the collected objects into records, filling missing keys with empty cells
+func (records *jsonRecords) toRecords() ([][]string, error) {
+	if len(records.lines) == 0 {
+		return nil, fmt.Errorf("JSON input does not contain any objects: %w", ErrNoRecords)
+	}
+
+	result := make([][]string, 0, len(records.lines)+1)
+	result = append(result, records.header)
+
+	for _, line := range records.lines {
+		values := make([]string, len(records.header))
+		for key, value := range line {
+			values[records.columns[key]] = value
+		}
+		result = append(result, values)
+	}
+
+	return result, nil
+}
+
+// Format a JSON value as a cell. Strings are unquoted, null and empty objects are empty and arrays are compacted JSON.
+func formatJSONValue(value json.RawMessage) (string, error) {
+	switch value[0] {
+	case '"':
+		var str string
+		err := json.Unmarshal(value, &str)
+		return str, err
+	case 'n', '{':
+		return "", nil
+	case '[':
+		var compacted bytes.Buffer
+		err := json.Compact(&compacted, value)
+		return compacted.String(), err
+	default:
+		// numbers and booleans are kept as written
+		return strings.TrimSpace(string(value)), nil
+	}
+}
+
+// Read the next token and check that it's the expected delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expected %s but found %v", delim, token)
+	}
+
+	return nil
+}
@@ -1,6 +1,13 @@
 package mdtable
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -399,3 +406,1871 @@ func TestEscapePipeCharacter(t *testing.T) {
 
 	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
 }
+
+/* COLUMN ALIGN */
+func TestConvertColumnAlign(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.ColumnAlign = map[string]Align{"#": Right, "gender": Center}
+
+	expected := `|  # | first name | last name  | email                       | gender |
+| -: | :--------- | :--------- | :-------------------------- | :----: |
+|  1 | Herman     | Gribbin    | hgribbin0@deliciousdays.com |  Male  |
+|  2 | Bing       | Langthorne | blangthorne1@a8.net         |  Male  |
+|  3 | Keith      | Hansford   | khansford2@reference.com    |  Male  |`
+
+	res, err := Convert(dataStringWithNarrowColumn, cfg)
+
+	assert.Nil(t, err, "Convert with column align should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestCompactConvertColumnAlign(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.ColumnAlign = map[string]Align{"First name": Left, "Phone": Right}
+
+	expected := `|First name|Last name|Email|Phone|
+|:-|:-:|:-:|-:|
+|Jane|Smith|jane.smith@email.com|555-555-1212|
+|John|Doe|john.doe@email.com|555-555-3434|
+|Alice|Wonder|alice@wonderland.com|555-555-5656|`
+
+	res, err := Convert(dataString, cfg)
+
+	assert.Nil(t, err, "Convert compact with column align should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertColumnAlignUnknownColumn(t *testing.T) {
+	var cfg Config
+	cfg.ColumnAlign = map[string]Align{"Address": Left}
+
+	res, err := Convert(dataString, cfg)
+
+	assert.NotNil(t, err, "Convert with column align on an unknown column should return an error")
+
+	assert.Empty(t, res, "String should be empty")
+}
+
+func TestValidateConfigColumnAlignOutOfRange(t *testing.T) {
+	var cfg Config
+	cfg.ColumnAlign = map[string]Align{"Email": Align(7)}
+
+	assert.NotNil(t, ValidateConfig(cfg), "ValidateConfig with an out of range column align should return an error")
+}
+
+/* AUTO ALIGN */
+var dataStringMixedTypes = [][]string{
+	{"Product", "Price", "Share", "In stock", "Quantity"},
+	{"Keyboard", "$49.99", "12.5%", "yes", "1,200"},
+	{"Mouse", "$19.00", "7%", "no", "35"},
+	{"Monitor", "$229.90", "80.5%", "yes", ""},
+}
+
+func TestConvertAutoAlign(t *testing.T) {
+	var cfg Config
+	cfg.Align = Auto
+
+	expected := `| Product  |   Price | Share | In stock | Quantity |
+| :------- | ------: | ----: | :------: | -------: |
+| Keyboard |  $49.99 | 12.5% |   yes    |    1,200 |
+| Mouse    |  $19.00 |    7% |    no    |       35 |
+| Monitor  | $229.90 | 80.5% |   yes    |          |`
+
+	res, err := Convert(dataStringMixedTypes, cfg)
+
+	assert.Nil(t, err, "Convert with auto align should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertAutoColumnAlign(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.ColumnAlign = map[string]Align{"Price": Auto, "Product": Auto}
+
+	expected := `|Product|Price|Share|In stock|Quantity|
+|:-|-:|:-:|:-:|:-:|
+|Keyboard|$49.99|12.5%|yes|1,200|
+|Mouse|$19.00|7%|no|35|
+|Monitor|$229.90|80.5%|yes||`
+
+	res, err := Convert(dataStringMixedTypes, cfg)
+
+	assert.Nil(t, err, "Convert with auto column align should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestIsNumeric(t *testing.T) {
+	for _, val := range []string{"0", "-12", "+3.5", ".5", "1,234,567.89", "45%", "$10", "10 €", "-$3.20", "$-3.20", "€ +7"} {
+		assert.True(t, isNumeric(val), "%q should be numeric", val)
+	}
+
+	for _, val := range []string{"", "-", "$", "1.2.3", "12,34", "555-555-1212", "abc", "v1.0", "--5", "+-5", "-$-5", "5-"} {
+		assert.False(t, isNumeric(val), "%q should not be numeric", val)
+	}
+}
+
+/* ROW SORTING */
+var dataStringForRowSorting = [][]string{
+	{"First name", "Last name", "Team"},
+	{"John", "Smith", "Blue"},
+	{"Alice", "Doe", "Red"},
+	{"Jane", "Smith", "Red"},
+	{"Bob", "Doe", "Blue"},
+}
+
+func TestConvertSortRowsMultipleKeys(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.SortRows = []RowSortKey{
+		{Column: "Last name", Order: Ascending},
+		{Column: "First name", Order: Ascending},
+	}
+
+	expected := `|First name|Last name|Team|
+|:-:|:-:|:-:|
+|Alice|Doe|Red|
+|Bob|Doe|Blue|
+|Jane|Smith|Red|
+|John|Smith|Blue|`
+
+	res, err := Convert(dataStringForRowSorting, cfg)
+
+	assert.Nil(t, err, "Convert with sorted rows should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	assert.Equal(t, "John", dataStringForRowSorting[1][0], "Convert should not reorder the lines of the caller")
+}
+
+func TestConvertSortRowsDescendingIsStable(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.SortRows = []RowSortKey{{Column: "Team", Order: Descending}}
+
+	expected := `|First name|Last name|Team|
+|:-:|:-:|:-:|
+|Alice|Doe|Red|
+|Jane|Smith|Red|
+|John|Smith|Blue|
+|Bob|Doe|Blue|`
+
+	res, err := Convert(dataStringForRowSorting, cfg)
+
+	assert.Nil(t, err, "Convert with sorted rows descending should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertSortRowsCustomFunction(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.SortRows = []RowSortKey{{Column: "First name", Order: Ascending, SortFunction: func(a, b string) int {
+		return len(a) - len(b)
+	}}}
+
+	expected := `|First name|Last name|Team|
+|:-:|:-:|:-:|
+|Bob|Doe|Blue|
+|John|Smith|Blue|
+|Jane|Smith|Red|
+|Alice|Doe|Red|`
+
+	res, err := Convert(dataStringForRowSorting, cfg)
+
+	assert.Nil(t, err, "Convert with sorted rows custom should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertSortRowsInvalidKey(t *testing.T) {
+	var cfg Config
+	cfg.SortRows = []RowSortKey{{Column: "Age", Order: Ascending}}
+
+	_, err := Convert(dataStringForRowSorting, cfg)
+
+	assert.NotNil(t, err, "Convert with sorted rows on an unknown column should return an error")
+
+	cfg.SortRows = []RowSortKey{{Column: "Team", Order: None}}
+
+	assert.NotNil(t, ValidateConfig(cfg), "ValidateConfig with sorted rows without a direction should return an error")
+}
+
+/* COMPARATORS */
+func TestCompareNumeric(t *testing.T) {
+	values := []string{"10", "n/a", "9", "-1.5", "$1,200", "50%"}
+	slices.SortFunc(values, CompareNumeric)
+
+	assert.Equal(t, []string{"-1.5", "9", "10", "50%", "$1,200", "n/a"}, values, "Values should be sorted numerically")
+
+	values = []string{"3", "--5", "$-2", "-$4", "+1"}
+	slices.SortFunc(values, CompareNumeric)
+
+	assert.Equal(t, []string{"-$4", "$-2", "+1", "3", "--5"}, values, "Signs should only be read from their position and invalid numbers sorted last")
+}
+
+func TestCompareNatural(t *testing.T) {
+	values := []string{"file10.txt", "file9.txt", "File1.txt", "file010.txt", "file"}
+	slices.SortFunc(values, CompareNatural)
+
+	assert.Equal(t, []string{"file", "File1.txt", "file9.txt", "file10.txt", "file010.txt"}, values, "Values should be sorted naturally")
+}
+
+func TestCompareSemVer(t *testing.T) {
+	values := []string{"v1.10.0", "1.9", "v1.10.0-rc.1", "1.10.0-alpha", "1.10.0-rc.2", "latest"}
+	slices.SortFunc(values, CompareSemVer)
+
+	assert.Equal(t, []string{"1.9", "1.10.0-alpha", "v1.10.0-rc.1", "1.10.0-rc.2", "v1.10.0", "latest"}, values, "Values should be sorted by semantic version")
+}
+
+func TestCompareDateTime(t *testing.T) {
+	values := []string{"2024-03-01", "2023-12-31T23:59:59Z", "2024-01-15 08:00", "unknown"}
+	slices.SortFunc(values, CompareDateTime)
+
+	assert.Equal(t, []string{"2023-12-31T23:59:59Z", "2024-01-15 08:00", "2024-03-01", "unknown"}, values, "Values should be sorted chronologically")
+}
+
+func TestCompareDuration(t *testing.T) {
+	values := []string{"1h30m", "250ms", "01:00:00", "45s", "00:02"}
+	slices.SortFunc(values, CompareDuration)
+
+	assert.Equal(t, []string{"250ms", "45s", "00:02", "01:00:00", "1h30m"}, values, "Values should be sorted by duration")
+}
+
+func TestConvertSortRowsWithComparator(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.SortRows = []RowSortKey{{Column: "Version", Order: Descending, Comparator: SemVer}}
+
+	records := [][]string{
+		{"Version", "Notes"},
+		{"v1.9.0", "Old"},
+		{"v1.10.0", "Latest"},
+		{"v1.10.0-rc.1", "Candidate"},
+	}
+
+	expected := `|Version|Notes|
+|:-:|:-:|
+|v1.10.0|Latest|
+|v1.10.0-rc.1|Candidate|
+|v1.9.0|Old|`
+
+	res, err := Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert with sorted rows using a comparator should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertSortColumnsWithComparator(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.SortColumns = Ascending
+	cfg.SortComparator = Natural
+
+	records := [][]string{
+		{"Q10", "Q9", "Q1"},
+		{"c", "b", "a"},
+	}
+
+	expected := `|Q1|Q9|Q10|
+|:-:|:-:|:-:|
+|a|b|c|`
+
+	res, err := Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert with sorted columns using a comparator should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestValidateConfigUnknownComparator(t *testing.T) {
+	var cfg Config
+	cfg.SortColumns = Ascending
+	cfg.SortComparator = "roman"
+
+	assert.NotNil(t, ValidateConfig(cfg), "ValidateConfig with an unknown comparator should return an error")
+}
+
+/* PARSE */
+func TestParseRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"ID", "Expression", "Description"},
+		{"1", "A || B", "Logical OR using pipe"},
+		{"2", "foo | bar | baz", "Chained pipe values"},
+		{"3", `x\`, `C:\temp\`},
+		{"4", `a\|b`, `\\server`},
+		{"5", `a\ |b\\`, `\*not emph\* 1\_000`},
+	}
+	expectedRecords := [][]string{
+		{"ID", "Expression", "Description"},
+		{"1", "A || B", "Logical OR using pipe"},
+		{"2", "foo | bar | baz", "Chained pipe values"},
+		{"3", `x\`, `C:\temp\`},
+		{"4", `a\|b`, `\\server`},
+		{"5", `a\ |b\\`, `\*not emph\* 1\_000`},
+	}
+
+	for _, compact := range []bool{false, true} {
+		var cfg Config
+		cfg.Caption = "Pipes"
+		cfg.Compact = compact
+		cfg.ColumnAlign = map[string]Align{"ID": Right, "Expression": Left}
+
+		converted, err := Convert(records, cfg)
+		assert.Nil(t, err, "Convert should not return a non-nil error")
+
+		table, err := Parse(converted)
+
+		assert.Nil(t, err, "Parse should not return a non-nil error")
+
+		assert.Equal(t, "Pipes", table.Caption, STRINGS_SHOULD_BE_THE_SAME)
+		assert.Equal(t, expectedRecords, table.Records, "Parsed records should be the same as the converted records")
+		assert.Equal(t, []Align{Right, Left, Center}, table.Align, "Parsed alignment should be the same as the converted alignment")
+		assert.Equal(t, map[string]Align{"ID": Right, "Expression": Left, "Description": Center}, table.ColumnAlign(), "Parsed column alignment should be keyed by header name")
+	}
+}
+
+func TestParseReaderHandwrittenTable(t *testing.T) {
+	markdown := `Some text before the table.
+
+Name | Score |Notes
+---|--:|-
+Alice | 10
+Bob|7|late|ignored
+
+Some text after the table.`
+
+	table, err := ParseReader(strings.NewReader(markdown))
+
+	assert.Nil(t, err, "ParseReader should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"Name", "Score", "Notes"},
+		{"Alice", "10", ""},
+		{"Bob", "7", "late"},
+	}, table.Records, "Missing cells should be empty and excess cells should be ignored")
+	assert.Equal(t, []Align{Left, Right, Left}, table.Align, "Columns without colons should be left aligned")
+	assert.Equal(t, map[string]Align{"Score": Right}, table.ColumnAlign(), "Only explicitly aligned columns should be in the column alignment")
+	assert.Empty(t, table.Caption, "Caption should be empty")
+}
+
+func TestParsedTableColumnAlignBuiltByCaller(t *testing.T) {
+	table := ParsedTable{
+		Records: [][]string{{"Name", "Score", "Notes"}, {"Alice", "10", ""}},
+		Align:   []Align{Left, Right},
+	}
+
+	assert.Equal(t, map[string]Align{"Name": Left, "Score": Right}, table.ColumnAlign(), "Alignments set by the caller should be explicit")
+	assert.Empty(t, ParsedTable{Records: table.Records}.ColumnAlign(), "Columns without alignment should be left out")
+}
+
+func TestParseNoTable(t *testing.T) {
+	_, err := Parse("| not a table |\njust | text")
+
+	assert.NotNil(t, err, "Parse without a table should return an error")
+}
+
+/* FORMAT */
+func TestFormatDocument(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.ColumnAlign = map[string]Align{"Qty": Right, "Unknown": Center}
+
+	document := "# Inventory\n\n" +
+		"Item|Qty\n" +
+		"---|:-:\n" +
+		"Apple | 3\n" +
+		"Banana|12\n\n" +
+		"```markdown\n" +
+		"a|b\n" +
+		"-|-\n" +
+		"```\n\n" +
+		"- list item\n\n" +
+		"  | Name | Role |\n" +
+		"  |--|--:|\n" +
+		"  | Ann | Owner |\n" +
+		"\nThe end\n"
+
+	expected := "# Inventory\n\n" +
+		"| Item   | Qty |\n" +
+		"| :----- | --: |\n" +
+		"| Apple  |   3 |\n" +
+		"| Banana |  12 |\n\n" +
+		"```markdown\n" +
+		"a|b\n" +
+		"-|-\n" +
+		"```\n\n" +
+		"- list item\n\n" +
+		"  | Name |  Role |\n" +
+		"  | :--- | ----: |\n" +
+		"  | Ann  | Owner |\n" +
+		"\nThe end\n"
+
+	res, err := Format(document, cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestFormatDocumentWithoutTables(t *testing.T) {
+	var cfg Config
+	document := "Plain | text without a separator\r\n\r\n~~~\n| a | b |\n| - | - |\n~~~"
+
+	res, err := Format(document, cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+
+	assert.Equal(t, document, res, "Document without tables should be left untouched")
+}
+
+func TestFormatDocumentFenceWithInfoString(t *testing.T) {
+	var cfg Config
+	document := "```\n```go\n| a | b |\n|---|---|\n| 1 | 2 |\n```\n\na|b\n-|-\n"
+
+	res, err := Format(document, cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+
+	assert.Equal(t, "```\n```go\n| a | b |\n|---|---|\n| 1 | 2 |\n```\n\n|  a  |  b  |\n| :-: | :-: |\n", res, "A fence with an info string should not close a code block")
+}
+
+func TestFormatDocumentWithLongRows(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	document := "| a | b |\n| - | - |\n| 1 | 2 | 3 |\n| 4 |"
+
+	_, err := Format(document, cfg)
+
+	assert.True(t, errors.Is(err, ErrRaggedRow), "Format should not drop the excess cells of long rows")
+
+	cfg.RaggedRows = RaggedExtendHeader
+	res, err := Format(document, cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+	assert.Equal(t, "|a|b|Column 3|\n|:-:|:-:|:-:|\n|1|2|3|\n|4|||", res, "Excess cells should be kept when the header line is extended")
+
+	// Parse keeps ignoring excess cells like GitHub does
+	table, err := Parse(document)
+
+	assert.Nil(t, err, "Parse should not return a non-nil error")
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}, {"4", ""}}, table.Records, "Parse should fit data lines to the header line")
+}
+
+func TestFormatDocumentKeepsMarkdownEscapes(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	document := "| a | b |\n| - | - |\n| \\*not emph\\* | 1\\_000 |\n| \\# title | C:\\\\ |"
+
+	res, err := Format(document, cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+	assert.Equal(t, "|a|b|\n|:-:|:-:|\n|\\*not emph\\*|1\\_000|\n|\\# title|C:\\\\|", res, "Markdown escapes in cells should be kept as they are")
+}
+
+func TestFormatDocumentLogsConfigWarningsOnce(t *testing.T) {
+	var logs strings.Builder
+	var cfg Config
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	cfg.SortComparator = Numeric
+
+	_, err := Format("a|b\n-|-\n1|2\n\nc|d\n-|-\n3|4\n", cfg)
+
+	assert.Nil(t, err, "Format should not return a non-nil error")
+	assert.Equal(t, 1, strings.Count(logs.String(), "level=WARN"), "Config warnings should be logged once per document")
+}
+
+/* MARKERS */
+func TestReplaceBetweenMarkers(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+
+	document := `# Project
+
+<!-- mdtable:start name=deps -->
+| stale | table |
+<!-- mdtable:end -->
+
+<!-- mdtable:start name=other -->
+keep me
+<!-- mdtable:end -->
+`
+
+	expected := `# Project
+
+<!-- mdtable:start name=deps -->
+|Name|Version|
+|:-:|:-:|
+|testify|v1.11.1|
+<!-- mdtable:end -->
+
+<!-- mdtable:start name=other -->
+keep me
+<!-- mdtable:end -->
+`
+
+	records := [][]string{{"Name", "Version"}, {"testify", "v1.11.1"}}
+
+	res, err := ReplaceBetweenMarkers(document, "deps", records, cfg)
+
+	assert.Nil(t, err, "ReplaceBetweenMarkers should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	// regenerating again should not change anything
+	res, err = ReplaceBetweenMarkers(res, "deps", records, cfg)
+
+	assert.Nil(t, err, "ReplaceBetweenMarkers should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestReplaceBetweenMarkersCRLF(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	document := "# Deps\r\n<!-- mdtable:start -->\r\nold\r\n<!-- mdtable:end -->\r\n"
+
+	res, err := ReplaceBetweenMarkers(document, "", [][]string{{"Name"}, {"testify"}}, cfg)
+
+	assert.Nil(t, err, "ReplaceBetweenMarkers should not return a non-nil error")
+
+	assert.Equal(t, "# Deps\r\n<!-- mdtable:start -->\r\n|Name|\r\n|:-:|\r\n|testify|\r\n<!-- mdtable:end -->\r\n", res, "Line endings of the document should be kept")
+}
+
+func TestReplaceBetweenMarkersSkipsCodeBlocks(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+
+	example := "Put the markers in your README:\n\n" +
+		"```\n" +
+		"```md\n" +
+		"<!-- mdtable:start name=deps -->\n" +
+		"<!-- mdtable:end -->\n" +
+		"```\n\n"
+
+	document := example + "<!-- mdtable:start name=deps -->\n" +
+		"~~~\n<!-- mdtable:end -->\n~~~\n" +
+		"<!-- mdtable:end -->\n"
+
+	expected := example + "<!-- mdtable:start name=deps -->\n" +
+		"|Name|\n|:-:|\n|testify|\n" +
+		"<!-- mdtable:end -->\n"
+
+	res, err := ReplaceBetweenMarkers(document, "deps", [][]string{{"Name"}, {"testify"}}, cfg)
+
+	assert.Nil(t, err, "ReplaceBetweenMarkers should not return a non-nil error")
+
+	assert.Equal(t, expected, res, "Markers inside fenced code blocks should be left untouched")
+
+	_, err = ReplaceBetweenMarkers(example, "deps", [][]string{{"Name"}, {"testify"}}, cfg)
+
+	assert.ErrorIs(t, err, ErrMarkerNotFound, "Markers inside fenced code blocks should not be found")
+}
+
+func TestReplaceBetweenMarkersMissingMarkers(t *testing.T) {
+	var cfg Config
+	records := [][]string{{"Name"}, {"testify"}}
+
+	_, err := ReplaceBetweenMarkers("no markers here", "deps", records, cfg)
+
+	assert.ErrorIs(t, err, ErrMarkerNotFound, "ReplaceBetweenMarkers without a start marker should return ErrMarkerNotFound")
+
+	_, err = ReplaceBetweenMarkers("<!-- mdtable:start name=deps -->\n", "deps", records, cfg)
+
+	assert.NotNil(t, err, "ReplaceBetweenMarkers without an end marker should return an error")
+}
+
+/* CSV */
+func TestReadCSVSemicolonWithBOM(t *testing.T) {
+	input := "\ufeffName; City ;Amount\n# exported 2024-01-31\nJürgen; Köln ;\"1.234,50\"\n\"Anne \"\"Nan\"\" Marie\";Paris;12\n"
+
+	records, err := ReadCSV(strings.NewReader(input), CSVOptions{Delimiter: ';', Comment: '#', TrimSpace: true})
+
+	assert.Nil(t, err, "ReadCSV should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"Name", "City", "Amount"},
+		{"Jürgen", "Köln", "1.234,50"},
+		{`Anne "Nan" Marie`, "Paris", "12"},
+	}, records, "Records should be read without BOM, comments and surrounding whitespace")
+}
+
+func TestReadCSVNoHeaderKeepBOM(t *testing.T) {
+	input := "\ufeffa|b\nc|d\n"
+
+	records, err := ReadCSV(strings.NewReader(input), CSVOptions{Delimiter: '|', NoHeader: true, KeepBOM: true})
+
+	assert.Nil(t, err, "ReadCSV should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"Column 1", "Column 2"},
+		{"\ufeffa", "b"},
+		{"c", "d"},
+	}, records, "Header should be generated and the BOM should be kept")
+}
+
+func TestReadTSV(t *testing.T) {
+	input := "Title\tQuote\nDune\tThe \"spice\" must flow\n"
+
+	records, err := ReadTSV(strings.NewReader(input), CSVOptions{})
+
+	assert.Nil(t, err, "ReadTSV should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"Title", "Quote"},
+		{"Dune", `The "spice" must flow`},
+	}, records, "Quotes inside fields should be read literally")
+}
+
+func TestReadCSVRaggedLines(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("a,b\n1\n"), CSVOptions{})
+
+	assert.NotNil(t, err, "ReadCSV with lines of different lengths should return an error")
+
+	records, err := ReadCSV(strings.NewReader("a,b\n1\n"), CSVOptions{AllowRagged: true})
+
+	assert.Nil(t, err, "ReadCSV with AllowRagged should not return a non-nil error")
+	assert.Equal(t, [][]string{{"a", "b"}, {"1"}}, records, "Ragged lines should be read as they are")
+}
+
+/* STRUCTS */
+type testLevel int
+
+func (level testLevel) String() string {
+	return strings.Repeat("★", int(level))
+}
+
+type testAudit struct {
+	CreatedBy string `mdtable:"Created by"`
+}
+
+type testIP [4]byte
+
+func (ip *testIP) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]), nil
+}
+
+type testEmployee struct {
+	Name     string
+	Age      int       `mdtable:",align=right"`
+	Level    testLevel `mdtable:"Skill level,align=left"`
+	Manager  *string
+	Address  testIP `mdtable:"IP"`
+	Password string `mdtable:"-"`
+	Internal string `mdtable:"Internal,omit"`
+	salary   int
+	testAudit
+}
+
+func TestConvertStructs(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.ColumnAlign = map[string]Align{"Name": Left}
+
+	manager := "Jane"
+	employees := []*testEmployee{
+		{Name: "John", Age: 42, Level: 3, Manager: &manager, Address: testIP{10, 0, 0, 1}, Password: "hunter2", salary: 1, testAudit: testAudit{"HR"}},
+		{Name: "Jane", Age: 51, Level: 5, Address: testIP{10, 0, 0, 2}},
+		nil,
+	}
+
+	expected := `|Name|Age|Skill level|Manager|IP|Created by|
+|:-|-:|:-|:-:|:-:|:-:|
+|John|42|★★★|Jane|10.0.0.1|HR|
+|Jane|51|★★★★★||10.0.0.2||
+|||||||`
+
+	res, err := ConvertStructs(employees, cfg)
+
+	assert.Nil(t, err, "ConvertStructs should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+type testMeta struct {
+	Secret   string
+	Internal string
+}
+
+func TestConvertStructsOmittedEmbeddedStruct(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+
+	type row struct {
+		testMeta   `mdtable:"-"`
+		Name       string
+		*testAudit `mdtable:",omit"`
+	}
+
+	res, err := ConvertStructs([]row{{testMeta: testMeta{"s", "i"}, Name: "John"}}, cfg)
+
+	assert.Nil(t, err, "ConvertStructs should not return a non-nil error")
+
+	assert.Equal(t, "|Name|\n|:-:|\n|John|", res, "Fields promoted from omitted embedded structs should be left out")
+}
+
+func TestConvertStructsInvalid(t *testing.T) {
+	var cfg Config
+
+	_, err := ConvertStructs([]int{1, 2}, cfg)
+
+	assert.NotNil(t, err, "ConvertStructs with a non-struct type should return an error")
+
+	_, err = ConvertStructs([]struct {
+		Name string `mdtable:",align=diagonal"`
+	}{{"x"}}, cfg)
+
+	assert.NotNil(t, err, "ConvertStructs with an unknown alignment in a tag should return an error")
+}
+
+/* JSON */
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{"name": "api", "stars": 1200, "owner": {"login": "alice", "email": "alice@example.com"}, "topics": ["go", "http"]},
+		{"name": "cli", "archived": true, "owner": {"login": "bob", "email": null}, "stars": 7.50},
+		{"name": "docs"}
+	]`
+
+	records, err := ReadJSON(strings.NewReader(input))
+
+	assert.Nil(t, err, "ReadJSON should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"name", "stars", "owner.login", "owner.email", "topics", "archived"},
+		{"api", "1200", "alice", "alice@example.com", `["go","http"]`, ""},
+		{"cli", "7.50", "bob", "", "", "true"},
+		{"docs", "", "", "", "", ""},
+	}, records, "Objects should be flattened into records with the union of keys")
+}
+
+func TestReadJSONLines(t *testing.T) {
+	input := `{"id": 1, "meta": {"tags": {"env": "prod"}}}
+
+{"id": 2, "status": "ok", "labels": {}}
+`
+
+	records, err := ReadJSONLines(strings.NewReader(input))
+
+	assert.Nil(t, err, "ReadJSONLines should not return a non-nil error")
+
+	assert.Equal(t, [][]string{
+		{"id", "meta.tags.env", "status", "labels"},
+		{"1", "prod", "", ""},
+		{"2", "", "ok", ""},
+	}, records, "Every line should be a record and empty objects should be empty cells")
+}
+
+func TestReadJSONInvalid(t *testing.T) {
+	_, err := ReadJSON(strings.NewReader(`{"id": 1}`))
+	assert.NotNil(t, err, "ReadJSON with an object instead of an array should return an error")
+
+	_, err = ReadJSON(strings.NewReader(`[{"id": 1}, 2]`))
+	assert.NotNil(t, err, "ReadJSON with an element that is not an object should return an error")
+
+	_, err = ReadJSON(strings.NewReader(`[]`))
+	assert.NotNil(t, err, "ReadJSON without objects should return an error")
+
+	_, err = ReadJSONLines(strings.NewReader("{\"id\": 1}\n{\"id\": "))
+	assert.NotNil(t, err, "ReadJSONLines with a truncated object should return an error")
+}
+
+/* HTML */
+func TestConvertHTML(t *testing.T) {
+	var cfg Config
+	cfg.Caption = "Q&A <draft>"
+	cfg.ExcludedColumns = []string{"Description"}
+	cfg.ColumnAlign = map[string]Align{"ID": Right}
+	cfg.Align = Left
+	cfg.SortColumns = Descending
+
+	records := [][]string{
+		{"ID", "Expression", "Description"},
+		{"1", "A || B", "Logical OR"},
+		{"2", `<b>"x" & 'y'</b>`, "Markup"},
+	}
+
+	expected := `<table>
+  <caption>Q&amp;A &lt;draft&gt;</caption>
+  <thead>
+    <tr>
+      <th style="text-align:right">ID</th>
+      <th style="text-align:left">Expression</th>
+    </tr>
+  </thead>
+  <tbody>
+    <tr>
+      <td style="text-align:right">1</td>
+      <td style="text-align:left">A || B</td>
+    </tr>
+    <tr>
+      <td style="text-align:right">2</td>
+      <td style="text-align:left">&lt;b&gt;&#34;x&#34; &amp; &#39;y&#39;&lt;/b&gt;</td>
+    </tr>
+  </tbody>
+</table>`
+
+	res, err := ConvertHTML(records, cfg)
+
+	assert.Nil(t, err, "ConvertHTML should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertHTMLCompactAutoAlign(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.Align = Auto
+
+	records := [][]string{{"Name", "Price"}, {"Pen", "1.50"}}
+
+	expected := `<table><thead><tr><th style="text-align:left">Name</th><th style="text-align:right">Price</th></tr></thead>` +
+		`<tbody><tr><td style="text-align:left">Pen</td><td style="text-align:right">1.50</td></tr></tbody></table>`
+
+	res, err := ConvertHTML(records, cfg)
+
+	assert.Nil(t, err, "ConvertHTML compact should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+/* RENDERERS */
+var dataStringForRenderers = [][]string{
+	{"Name", "Qty", "Note"},
+	{"A_b", "10", "x|y"},
+	{"Long name", "2", ""},
+}
+
+func TestConvertRenderers(t *testing.T) {
+	testCases := []struct {
+		renderer string
+		compact  bool
+		expected string
+	}{
+		{"rst-grid", false, `.. table:: Stock
+
+   +-----------+-----+------+
+   | Name      | Qty | Note |
+   +===========+=====+======+
+   | A_b       |  10 | x|y  |
+   +-----------+-----+------+
+   | Long name |   2 |      |
+   +-----------+-----+------+`},
+		{"rst-simple", false, `.. table:: Stock
+
+   =========  ===  ====
+   Name       Qty  Note
+   =========  ===  ====
+   A_b         10  x|y
+   Long name    2
+   =========  ===  ====`},
+		{"asciidoc", false, `.Stock
+[cols="<,>,<",options="header"]
+|===
+| Name      | Qty | Note
+| A_b       |  10 | x\|y
+| Long name |   2 |
+|===`},
+		{"org", true, `#+CAPTION: Stock
+|Name|Qty|Note|
+|-+-+-|
+|A_b|10|x\vert{}y|
+|Long name|2||`},
+		{"mediawiki", true, `{| class="wikitable"
+|+ Stock
+! style="text-align:left" | Name !! style="text-align:right" | Qty !! style="text-align:left" | Note
+|-
+| style="text-align:left" | A_b || style="text-align:right" | 10 || style="text-align:left" | x&#124;y
+|-
+| style="text-align:left" | Long name || style="text-align:right" | 2 || style="text-align:left" |
+|}`},
+		{"jira", true, `*Stock*
+||Name||Qty||Note||
+|A_b|10|x\|y|
+|Long name|2| |`},
+		{"latex", false, `\begin{table}
+\centering
+\caption{Stock}
+\begin{tabular}{lrl}
+\hline
+Name      & Qty & Note \\
+\hline
+A\_b      &  10 & x|y  \\
+Long name &   2 &      \\
+\hline
+\end{tabular}
+\end{table}`},
+	}
+
+	for _, testCase := range testCases {
+		var cfg Config
+		cfg.Align = Auto
+		cfg.Caption = "Stock"
+		cfg.Compact = testCase.compact
+		cfg.Renderer = testCase.renderer
+
+		res, err := Convert(dataStringForRenderers, cfg)
+
+		assert.Nil(t, err, "Convert with renderer %s should not return a non-nil error", testCase.renderer)
+
+		assert.Equal(t, testCase.expected, res, "%s: %s", testCase.renderer, STRINGS_SHOULD_BE_THE_SAME)
+	}
+}
+
+func TestConvertRSTGridHeaderOnly(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "rst-grid"
+	cfg.Align = Left
+
+	expected := `+------+-----+
+| Name | Qty |
++------+-----+`
+
+	res, err := Convert([][]string{{"Name", "Qty"}}, cfg)
+
+	assert.Nil(t, err, "Convert header only with renderer rst-grid should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertRSTSimpleEmptyCells(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "rst-simple"
+	cfg.Align = Left
+
+	// an empty first cell is an empty comment and empty columns still get a border
+	expected := `==  =  ===
+ID     Qty
+==  =  ===
+1
+..
+..     2
+==  =  ===`
+
+	res, err := Convert([][]string{{"ID", "", "Qty"}, {"1", "", ""}, {"", "", " "}, {"", "", "2"}}, cfg)
+
+	assert.Nil(t, err, "Convert with renderer rst-simple should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+// renders every line as comma-separated cells, used to test custom renderers
+type testCSVRenderer struct{}
+
+func (testCSVRenderer) Escape(value string) string {
+	return strings.ReplaceAll(value, ",", ";")
+}
+
+func (testCSVRenderer) Header(w io.Writer, layout TableLayout) error {
+	_, err := io.WriteString(w, strings.Join(layout.Header, ",")+"\n")
+	return err
+}
+
+func (testCSVRenderer) Separator(w io.Writer, layout TableLayout) error {
+	return nil
+}
+
+func (testCSVRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	_, err := fmt.Fprintf(w, "%d:%s\n", rowIdx, strings.Join(cells, ","))
+	return err
+}
+
+func (testCSVRenderer) Footer(w io.Writer, layout TableLayout) error {
+	_, err := fmt.Fprintf(w, "%d rows\n", layout.Rows)
+	return err
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "test-csv"
+
+	assert.NotNil(t, ValidateConfig(cfg), "ValidateConfig with an unregistered renderer should return an error")
+
+	assert.Nil(t, RegisterRenderer("test-csv", testCSVRenderer{}), "RegisterRenderer should not return a non-nil error")
+	assert.NotNil(t, RegisterRenderer("", testCSVRenderer{}), "RegisterRenderer without a name should return an error")
+	assert.NotNil(t, RegisterRenderer("nil", nil), "RegisterRenderer without a renderer should return an error")
+	assert.Contains(t, RendererNames(), "test-csv", "Registered renderer should be listed")
+
+	cfg.ExcludedColumns = []string{"Email"}
+	res, err := Convert(dataString, cfg)
+
+	expected := `First name,Last name,Phone
+0:Jane,Smith,555-555-1212
+1:John,Doe,555-555-3434
+2:Alice,Wonder,555-555-5656
+3 rows`
+
+	assert.Nil(t, err, "Convert with a registered renderer should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+/* DISPLAY WIDTH */
+func TestDisplayWidth(t *testing.T) {
+	testCases := map[string]int{
+		"":                     0,
+		"abc":                  3,
+		"日本語":                  6,
+		"ｈｉ":                   4,
+		"한국어":                  6,
+		"caf\u00e9":            4,
+		"cafe\u0301":           4,
+		"\U0001F600":           2,
+		"\U0001F44D\U0001F3FD": 2,
+		"\U0001F468\u200d\U0001F469\u200d\U0001F467": 2,
+		"\U0001F1EB\U0001F1EE\U0001F1EF\U0001F1F5":   4,
+		"a\u200bb": 2,
+	}
+
+	for value, expected := range testCases {
+		assert.Equal(t, expected, displayWidth(value), "Display width of %q", value)
+	}
+}
+
+func TestConvertWideCharacters(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+
+	records := [][]string{
+		{"Name", "City", "Mood"},
+		{"山田太郎", "東京", "\U0001F600"},
+		{"Jose\u0301", "Zürich", "\U0001F44D\U0001F3FD"},
+		{"Zoë", "Réunion", "\U0001F1EB\U0001F1EE"},
+	}
+
+	expected := "| Name     | City    | Mood |\n" +
+		"| :------- | :------ | :--- |\n" +
+		"| 山田太郎 | 東京    | \U0001F600   |\n" +
+		"| Jose\u0301     | Zürich  | \U0001F44D\U0001F3FD   |\n" +
+		"| Zoë      | Réunion | \U0001F1EB\U0001F1EE   |"
+
+	res, err := Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert with wide characters should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestPadCenterWideCharacters(t *testing.T) {
+	res, err := padCenter("中文", 7, ' ')
+
+	assert.Nil(t, err, "padCenter should not return a non-nil error")
+
+	assert.Equal(t, " 中文  ", res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+/* TERMINAL */
+func TestConvertTerminal(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "terminal"
+	cfg.Align = Auto
+	cfg.Caption = "Stock"
+
+	expected := `Stock
+┌───────────┬─────┬──────┐
+│ Name      │ Qty │ Note │
+├───────────┼─────┼──────┤
+│ A_b       │  10 │ x|y  │
+│ Long name │   2 │      │
+└───────────┴─────┴──────┘`
+
+	res, err := Convert(dataStringForRenderers, cfg)
+
+	assert.Nil(t, err, "Convert with renderer terminal should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertTerminalBorderStyles(t *testing.T) {
+	records := [][]string{{"A", "B"}, {"1", "2"}}
+
+	expected := map[BorderStyle]string{
+		Double:  "╔═══╦═══╗\n║ A ║ B ║\n╠═══╬═══╣\n║ 1 ║ 2 ║\n╚═══╩═══╝",
+		Rounded: "╭───┬───╮\n│ A │ B │\n├───┼───┤\n│ 1 │ 2 │\n╰───┴───╯",
+		ASCII:   "+-+-+\n|A|B|\n+-+-+\n|1|2|\n+-+-+",
+	}
+
+	for style, table := range expected {
+		var cfg Config
+		cfg.Renderer = "terminal"
+		cfg.BorderStyle = style
+		// ASCII table is compact to check that cells are not surrounded by spaces
+		cfg.Compact = style == ASCII
+
+		res, err := Convert(records, cfg)
+
+		assert.Nil(t, err, "Convert with border style %d should not return a non-nil error", style)
+
+		assert.Equal(t, table, res, STRINGS_SHOULD_BE_THE_SAME)
+	}
+
+	assert.NotNil(t, ValidateConfig(Config{BorderStyle: BorderStyle(9)}), "ValidateConfig with an out of range border style should return an error")
+}
+
+func TestConvertTerminalColors(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "terminal"
+	cfg.Align = Left
+	cfg.BorderStyle = ASCII
+	cfg.HeaderColor = Color(Bold + ";" + Cyan)
+	cfg.BorderColor = Dim
+
+	// cells that are already colored must not be wider than they look
+	records := [][]string{{"Status", "Name"}, {"\x1b[32mOK\x1b[0m", "api"}, {"\x1b[31mFAILED\x1b[0m", "\x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\"}}
+
+	expected := "\x1b[2m+--------+------+\x1b[0m\n" +
+		"\x1b[2m|\x1b[0m\x1b[1;36m Status \x1b[0m\x1b[2m|\x1b[0m\x1b[1;36m Name \x1b[0m\x1b[2m|\x1b[0m\n" +
+		"\x1b[2m+--------+------+\x1b[0m\n" +
+		"\x1b[2m|\x1b[0m \x1b[32mOK\x1b[0m     \x1b[2m|\x1b[0m api  \x1b[2m|\x1b[0m\n" +
+		"\x1b[2m|\x1b[0m \x1b[31mFAILED\x1b[0m \x1b[2m|\x1b[0m \x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\ \x1b[2m|\x1b[0m\n" +
+		"\x1b[2m+--------+------+\x1b[0m"
+
+	res, err := Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert with terminal colors should not return a non-nil error")
+
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+/* ENCODER */
+func TestEncoderBuffered(t *testing.T) {
+	var cfg Config
+	var result strings.Builder
+
+	encoder := NewEncoder(&result, cfg)
+
+	assert.Nil(t, encoder.WriteHeader(dataString[0]), "WriteHeader should not return a non-nil error")
+	for _, row := range dataString[1:] {
+		assert.Nil(t, encoder.WriteRow(row), "WriteRow should not return a non-nil error")
+	}
+
+	assert.Empty(t, result.String(), "Nothing should be written before Flush")
+
+	assert.Nil(t, encoder.Flush(), "Flush should not return a non-nil error")
+
+	expected, err := Convert(dataString, cfg)
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+
+	assert.Equal(t, expected+"\n", result.String(), "Buffered Encoder should write the same table as Convert")
+
+	assert.NotNil(t, encoder.WriteRow(dataString[1]), "WriteRow after Flush should return an error")
+}
+
+func TestEncoderStreaming(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.ColumnAlign = map[string]Align{"Qty": Right}
+	cfg.ExcludedColumns = []string{"Secret"}
+	var result strings.Builder
+
+	encoder := NewEncoder(&result, cfg)
+	encoder.SetFixedWidth(4)
+	encoder.SetColumnWidths(map[string]int{"Name": 8})
+
+	assert.Nil(t, encoder.WriteHeader([]string{"Name", "Secret", "Qty"}), "WriteHeader should not return a non-nil error")
+
+	assert.Equal(t, "| Name     |  Qty |\n", result.String(), "Header should be written right away")
+
+	assert.Nil(t, encoder.WriteRow([]string{"Apple", "x", "3"}), "WriteRow should not return a non-nil error")
+	assert.Nil(t, encoder.WriteRow([]string{"Dragon fruit", "y", "12"}), "WriteRow should not return a non-nil error")
+
+	assert.Equal(t, "| Name     |  Qty |\n| :------- | ---: |\n| Apple    |    3 |\n| Dragon fruit |   12 |\n", result.String(), "Rows should be written right away")
+
+	assert.Nil(t, encoder.Flush(), "Flush should not return a non-nil error")
+
+	assert.NotNil(t, encoder.WriteRow([]string{"Kiwi"}), "WriteRow after Flush should return an error")
+}
+
+func TestEncoderStreamingHTML(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "html"
+	cfg.Compact = true
+	var result strings.Builder
+
+	encoder := NewEncoder(&result, cfg)
+	encoder.SetFixedWidth(1)
+
+	assert.Nil(t, encoder.WriteHeader([]string{"A"}), "WriteHeader should not return a non-nil error")
+	assert.Nil(t, encoder.Flush(), "Flush should not return a non-nil error")
+
+	assert.Equal(t, `<table><thead><tr><th style="text-align:center">A</th></tr></thead></table>`, result.String(), STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestEncoderErrors(t *testing.T) {
+	var cfg Config
+	var result strings.Builder
+
+	encoder := NewEncoder(&result, cfg)
+	assert.NotNil(t, encoder.WriteRow([]string{"a"}), "WriteRow before WriteHeader should return an error")
+
+	encoder = NewEncoder(&result, cfg)
+	assert.Nil(t, encoder.WriteHeader([]string{"a", "b"}), "WriteHeader should not return a non-nil error")
+	assert.NotNil(t, encoder.WriteRow([]string{"a"}), "WriteRow with a missing field should return an error")
+	assert.NotNil(t, encoder.Flush(), "Flush after a failed write should return the error")
+
+	cfg.SortRows = []RowSortKey{{Column: "a", Order: Ascending}}
+	encoder = NewEncoder(&result, cfg)
+	encoder.SetFixedWidth(3)
+	assert.NotNil(t, encoder.WriteHeader([]string{"a"}), "Streaming with SortRows should return an error")
+}
+
+/* RAGGED ROWS */
+var raggedRecords = [][]string{
+	{"Name", "Age"},
+	{"Alice"},
+	{"Bob", "42", "admin"},
+}
+
+func TestConvertRaggedRowsError(t *testing.T) {
+	var cfg Config
+
+	original := cloneRecords(raggedRecords)
+
+	_, err := Convert(raggedRecords, cfg)
+
+	assert.EqualError(t, err, "row 1 col 1: row does not fit the header line, it has 1 fields but the header has 2", "Short rows should be reported with their position")
+	assert.Equal(t, original, raggedRecords, "Convert should not modify the records")
+
+	cfg.RaggedRows = RaggedPad
+	_, err = Convert(raggedRecords, cfg)
+
+	assert.EqualError(t, err, "row 2 col 2: row does not fit the header line, it has 3 fields but the header has 2", "Long rows should be reported with their position")
+
+	var colErr *ColumnError
+	assert.True(t, errors.As(err, &colErr), "Ragged rows should be reported as a ColumnError")
+	assert.Equal(t, ColumnError{Row: 2, Col: 2, Value: "admin", Err: colErr.Err}, *colErr, "ColumnError should hold the position and value of the extra field")
+	assert.True(t, errors.Is(err, ErrRaggedRow), "Ragged rows should wrap ErrRaggedRow")
+}
+
+func TestConvertRaggedRowsTruncate(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.RaggedRows = RaggedTruncate
+
+	expected := `|Name|Age|
+|:-:|:-:|
+|Alice||
+|Bob|42|`
+
+	res, err := Convert(raggedRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertRaggedRowsExtendHeader(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.RaggedRows = RaggedExtendHeader
+
+	expected := `|Name|Age|Column 3|
+|:-:|:-:|:-:|
+|Alice|||
+|Bob|42|admin|`
+
+	res, err := Convert(raggedRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestEncoderRaggedRows(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.RaggedRows = RaggedExtendHeader
+
+	var buffered strings.Builder
+	encoder := NewEncoder(&buffered, cfg)
+	assert.Nil(t, encoder.WriteHeader(raggedRecords[0]), "WriteHeader should not return a non-nil error")
+	for _, row := range raggedRecords[1:] {
+		assert.Nil(t, encoder.WriteRow(row), "WriteRow should not return a non-nil error")
+	}
+	assert.Nil(t, encoder.Flush(), "Flush should not return a non-nil error")
+
+	expected, _ := Convert(raggedRecords, cfg)
+	assert.Equal(t, expected+"\n", buffered.String(), "Buffered rows should extend the header line like Convert does")
+
+	// the header line was already written when streaming
+	var streamed strings.Builder
+	encoder = NewEncoder(&streamed, cfg)
+	encoder.SetFixedWidth(1)
+	assert.Nil(t, encoder.WriteHeader(raggedRecords[0]), "WriteHeader should not return a non-nil error")
+	assert.Nil(t, encoder.WriteRow(raggedRecords[1]), "Short rows should be padded")
+	assert.NotNil(t, encoder.WriteRow(raggedRecords[2]), "Long rows can't extend a header line that was already written")
+}
+
+/* EMPTY INPUT */
+func TestConvertNoRecords(t *testing.T) {
+	var cfg Config
+
+	for _, records := range [][][]string{nil, {}, {{}}} {
+		res, err := Convert(records, cfg)
+
+		assert.True(t, errors.Is(err, ErrNoRecords), "Convert without records should return ErrNoRecords")
+		assert.Empty(t, res, "Convert without records should return an empty string")
+	}
+}
+
+func TestConvertHeaderOnly(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+
+	expected := `| Name | Age |
+| :--- | :-- |`
+
+	res, err := Convert([][]string{{"Name", "Age"}}, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertEmptyPlaceholder(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.EmptyPlaceholder = "_No results_"
+
+	expected := `| Name         | Age |
+| :----------- | :-- |
+| _No results_ |     |`
+
+	res, err := Convert([][]string{{"Name", "Age"}}, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	// tables with data lines don't get a placeholder
+	res, err = Convert([][]string{{"Name", "Age"}, {"Alice", "30"}}, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.NotContains(t, res, "_No results_", "Placeholder should only be rendered for header-only tables")
+}
+
+func TestConvertEmptyPlaceholderHTML(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.Renderer = "html"
+	cfg.EmptyPlaceholder = "No results <yet>"
+
+	expected := `<table><thead><tr><th style="text-align:center">Name</th><th style="text-align:center">Age</th></tr></thead>` +
+		`<tbody><tr><td colspan="2" style="text-align:center">No results &lt;yet&gt;</td></tr></tbody></table>`
+
+	res, err := Convert([][]string{{"Name", "Age"}}, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestEncoderEmptyPlaceholder(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.EmptyPlaceholder = "_No results_"
+
+	var result strings.Builder
+	encoder := NewEncoder(&result, cfg)
+	encoder.SetFixedWidth(4)
+	assert.Nil(t, encoder.WriteHeader([]string{"Name", "Age"}), "WriteHeader should not return a non-nil error")
+	assert.Nil(t, encoder.Flush(), "Flush should not return a non-nil error")
+
+	expected := `| Name | Age  |
+| :--- | :--- |
+| _No results_ |      |
+`
+
+	assert.Equal(t, expected, result.String(), STRINGS_SHOULD_BE_THE_SAME)
+}
+
+/* LOGGING */
+func TestConvertLogger(t *testing.T) {
+	var logs strings.Builder
+	var cfg Config
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg.ExcludedColumns = []string{"Name", "Age"}
+
+	_, err := Convert([][]string{{"Name", "Age"}, {"Alice", "30"}}, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Contains(t, logs.String(), "level=WARN", "Warnings should be written to the logger of the config")
+	assert.NotContains(t, logs.String(), "level=DEBUG", "Debug messages should only be logged with VerboseLogging")
+}
+
+func TestConvertVerboseLogging(t *testing.T) {
+	var logs strings.Builder
+	var cfg Config
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg.VerboseLogging = true
+	cfg.ExcludedColumns = []string{"Age"}
+	cfg.SortRows = []RowSortKey{{Column: "Name", Order: Ascending}}
+
+	records := [][]string{{"Name", "Age"}, {"Bob", "42"}, {"Alice", "30"}}
+	_, err := Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	for _, msg := range []string{"Config is valid", "Sorting data lines", "Resolved excluded columns", "Resolved column order", "Computed column widths"} {
+		assert.Contains(t, logs.String(), msg, "Verbose logging should log every step of the conversion")
+	}
+
+	assert.Contains(t, logs.String(), "align=[Center]", "Alignments should be logged by name")
+
+	// without a logger, every message goes to the handler of the default logger and its level stays as it is
+	var defaultLogs strings.Builder
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&defaultLogs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	cfg.Logger = nil
+	cfg.ExcludedColumns = []string{"Missing"}
+	_, err = Convert(records, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Contains(t, defaultLogs.String(), `"level":"WARN","msg":"Excluded column`, "Warnings should go to the default logger")
+	assert.Contains(t, defaultLogs.String(), `"level":"DEBUG","msg":"Computed column widths"`, "Verbose messages should go to the handler of the default logger")
+	assert.False(t, slog.Default().Enabled(t.Context(), slog.LevelDebug), "VerboseLogging should not change the level of the default logger")
+}
+
+/* REPORT */
+func TestConvertWithReport(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	cfg.ExcludedColumns = []string{"Email", "Phone"}
+	cfg.SortComparator = Numeric
+	cfg.SortRows = []RowSortKey{{Column: "Name", Order: Ascending, Comparator: Natural, SortFunction: strings.Compare}}
+
+	records := [][]string{
+		{"Name", "Email", "Age"},
+		{"Bob", "bob@example.com", "42"},
+		{"Alice", "alice@example.com", "7"},
+	}
+
+	expected := `| Name  | Age |
+| :---- | :-- |
+| Alice | 7   |
+| Bob   | 42  |`
+
+	res, report, err := ConvertWithReport(records, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	var codes []WarningCode
+	for _, warning := range report.Warnings {
+		codes = append(codes, warning.Code)
+	}
+
+	assert.Equal(t, []WarningCode{WarnComparatorIgnored, WarnSortComparatorIgnored, WarnExcludedColumnNotFound}, codes, "Every warning should be reported in order")
+	assert.Equal(t, "Name", report.Warnings[0].Column, "Warnings about a column should name it")
+	assert.Equal(t, "Phone", report.Warnings[2].Column, "Warnings about a column should name it")
+	assert.True(t, report.HasWarning(WarnExcludedColumnNotFound), "HasWarning should find reported warnings")
+	assert.False(t, report.HasWarning(WarnAllColumnsExcluded), "HasWarning should not find warnings that were not reported")
+
+	assert.Equal(t, 2, report.Rows, "Rows should count the data lines")
+	assert.Equal(t, []string{"Name", "Age"}, report.Columns, "Columns should list the rendered columns")
+	assert.Equal(t, []string{"Email"}, report.DroppedColumns, "DroppedColumns should list the excluded columns")
+	assert.Equal(t, []int{5, 3}, report.Widths, "Widths should be the widths of the rendered columns")
+	assert.Equal(t, 15, report.Width, "Width should be the width of the longest line")
+}
+
+func TestConvertWithReportRenderedWidths(t *testing.T) {
+	var cfg Config
+	records := [][]string{{"#", "Name"}, {"1", "Alice"}}
+
+	res, report, err := ConvertWithReport(records, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Equal(t, []int{3, 5}, report.Widths, "Widths should include the widening of narrow Markdown columns")
+	assert.Equal(t, displayWidth(strings.Split(res, "\n")[0]), report.Width, "Width should be the width of the rendered lines")
+
+	cfg.Renderer = "html"
+	_, report, err = ConvertWithReport(records, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Equal(t, []int{1, 5}, report.Widths, "Renderers that don't widen columns should report the widths of the values")
+}
+
+func TestConvertWithReportAllExcluded(t *testing.T) {
+	var cfg Config
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	cfg.ExcludedColumns = []string{"Name"}
+	cfg.EmptyPlaceholder = "_No results_"
+
+	res, report, err := ConvertWithReport([][]string{{"Name"}}, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Empty(t, res, "Nothing should be rendered when all columns are excluded")
+	assert.True(t, report.HasWarning(WarnAllColumnsExcluded), "Excluding every column should be reported")
+	assert.Equal(t, []string{"Name"}, report.DroppedColumns, "DroppedColumns should list the excluded columns")
+
+	cfg.ExcludedColumns = nil
+	_, report, err = ConvertWithReport([][]string{{"Name"}}, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Empty(t, report.Warnings, "A valid config should not raise warnings")
+	assert.Equal(t, 0, report.Rows, "The placeholder should not be counted as a data line")
+}
+
+/* ERRORS */
+func TestValidateConfigJoinsErrors(t *testing.T) {
+	var cfg Config
+	cfg.Align = 7
+	cfg.SortColumns = Custom
+	cfg.SortComparator = "roman"
+	cfg.ColumnAlign = map[string]Align{"b": -1, "a": 9}
+
+	err := ValidateConfig(cfg)
+
+	for _, target := range []error{ErrInvalidAlign, ErrMissingSortFunc, ErrUnknownComparator} {
+		assert.True(t, errors.Is(err, target), "Every problem of the config should be reported")
+	}
+	assert.False(t, errors.Is(err, ErrUnknownRenderer), "Valid settings should not be reported")
+
+	expected := `align value is out of range, please choose in range [0-3]
+column "a": align value is out of range, please choose in range [0-3]
+column "b": align value is out of range, please choose in range [0-3]
+sort type is set to Custom but SortFunc was not set
+sort comparator "roman": not a built-in comparator`
+
+	assert.EqualError(t, err, expected, "Problems should be reported one per line, in a stable order")
+}
+
+func TestConvertConfigErrors(t *testing.T) {
+	var cfg Config
+	cfg.Renderer = "pdf"
+
+	_, err := Convert(dataString, cfg)
+
+	assert.True(t, errors.Is(err, ErrInvalidConfig), "Config problems should wrap ErrInvalidConfig")
+	assert.True(t, errors.Is(err, ErrUnknownRenderer), "Config problems should keep their own error")
+
+	cfg.Renderer = ""
+	cfg.ColumnAlign = map[string]Align{"Missing": Left}
+	cfg.SortRows = []RowSortKey{{Column: "Unknown", Order: Ascending}}
+
+	_, err = Convert(dataString, cfg)
+
+	assert.True(t, errors.Is(err, ErrInvalidConfig), "Unknown columns should wrap ErrInvalidConfig")
+	assert.True(t, errors.Is(err, ErrUnknownColumn), "Unknown columns should wrap ErrUnknownColumn")
+	assert.Contains(t, err.Error(), `column "Missing" in ColumnAlign`, "Every unknown column should be reported")
+	assert.Contains(t, err.Error(), `column "Unknown" in SortRows`, "Every unknown column should be reported")
+}
+
+func TestConvertColumnError(t *testing.T) {
+	layout := TableLayout{Widths: []int{3, 2}, Align: []Align{Left, Left}}
+	_, err := constructBeautifulDataLine([]string{"abc", "wide"}, layout, 4)
+
+	var colErr *ColumnError
+	assert.True(t, errors.As(err, &colErr), "Padding errors should be reported as a ColumnError")
+	assert.Equal(t, 4, colErr.Row, "ColumnError should hold the row of the value")
+	assert.Equal(t, 1, colErr.Col, "ColumnError should hold the column of the value")
+	assert.Equal(t, "wide", colErr.Value, "ColumnError should hold the value")
+	assert.True(t, errors.Is(err, ErrValueTooWide), "Padding errors should wrap ErrValueTooWide")
+}
+
+/* COLUMN ORDER */
+var columnOrderRecords = [][]string{
+	{"Name", "ID", "Email", "Amount"},
+	{"Alice", "2", "alice@example.com", "7"},
+	{"Bob", "1", "bob@example.com", "42"},
+}
+
+func TestConvertColumnOrder(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	cfg.ColumnOrder = []string{"Amount", "ID", "Name"}
+	cfg.SortColumns = Ascending
+
+	expected := `|Amount|ID|Name|
+|:-:|:-:|:-:|
+|7|2|Alice|
+|42|1|Bob|`
+
+	res, report, err := ConvertWithReport(columnOrderRecords, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+	assert.Equal(t, []string{"Email"}, report.DroppedColumns, "Columns missing from ColumnOrder should be dropped")
+	assert.True(t, report.HasWarning(WarnSortColumnsIgnored), "SortColumns should be reported as ignored")
+
+	// excluded columns are left out even if they are listed
+	cfg.ExcludedColumns = []string{"ID"}
+	cfg.SortColumns = None
+
+	expected = `|Amount|Name|
+|:-:|:-:|
+|7|Alice|
+|42|Bob|`
+
+	res, err = Convert(columnOrderRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	cfg.ColumnOrder = []string{"ID"}
+	res, report, err = ConvertWithReport(columnOrderRecords, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Empty(t, res, "Nothing should be rendered when every ordered column is excluded")
+	assert.True(t, report.HasWarning(WarnAllColumnsExcluded), "Excluding every ordered column should be reported")
+}
+
+func TestConvertPinnedColumns(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.PinnedColumns = []string{"ID"}
+	cfg.SortColumns = Ascending
+
+	expected := `|ID|Amount|Email|Name|
+|:-:|:-:|:-:|:-:|
+|2|7|alice@example.com|Alice|
+|1|42|bob@example.com|Bob|`
+
+	res, err := Convert(columnOrderRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+
+	// pinned columns are rendered even when ColumnOrder doesn't list them
+	cfg.SortColumns = None
+	cfg.ColumnOrder = []string{"Name", "Amount"}
+
+	expected = `|ID|Name|Amount|
+|:-:|:-:|:-:|
+|2|Alice|7|
+|1|Bob|42|`
+
+	res, err = Convert(columnOrderRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestConvertColumnOrderUnknownColumns(t *testing.T) {
+	var cfg Config
+	cfg.ColumnOrder = []string{"Name", "Phone"}
+	cfg.PinnedColumns = []string{"Key"}
+
+	_, err := Convert(columnOrderRecords, cfg)
+
+	assert.True(t, errors.Is(err, ErrUnknownColumn), "Unknown columns should wrap ErrUnknownColumn")
+	assert.Contains(t, err.Error(), `column "Phone" in ColumnOrder`, "Unknown ordered columns should be reported")
+	assert.Contains(t, err.Error(), `column "Key" in PinnedColumns`, "Unknown pinned columns should be reported")
+}
+
+/* COLUMN MATCHERS */
+var matcherRecords = [][]string{
+	{"ID", "Name", "internal_id", "Created_meta", "internal_flags", "Score"},
+	{"1", "Alice", "a1", "2024", "x", "7"},
+}
+
+func TestConvertExcludeMatchers(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	cfg.ExcludeMatchers = []ColumnMatcher{Glob("internal_*"), Regexp("_meta$"), FoldName("score"), Indices("10-12")}
+
+	expected := `|ID|Name|
+|:-:|:-:|
+|1|Alice|`
+
+	res, report, err := ConvertWithReport(matcherRecords, cfg)
+
+	assert.Nil(t, err, "ConvertWithReport should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+	assert.Equal(t, []string{"internal_id", "Created_meta", "internal_flags", "Score"}, report.DroppedColumns, "Matched columns should be dropped")
+
+	assert.Len(t, report.Warnings, 1, "Only the pattern that matched nothing should be reported")
+	assert.Equal(t, WarnPatternNotMatched, report.Warnings[0].Code, "Patterns that matched nothing should be reported")
+	assert.Equal(t, "10-12", report.Warnings[0].Column, "The warning should name the pattern")
+}
+
+func TestConvertIncludeMatchers(t *testing.T) {
+	var cfg Config
+	cfg.Compact = true
+	cfg.IncludeMatchers = []ColumnMatcher{Indices("0-1,5"), ExactName("internal_id")}
+	cfg.ExcludedColumns = []string{"Name"}
+
+	expected := `|ID|internal_id|Score|
+|:-:|:-:|:-:|
+|1|a1|7|`
+
+	res, err := Convert(matcherRecords, cfg)
+
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+	assert.Equal(t, expected, res, STRINGS_SHOULD_BE_THE_SAME)
+}
+
+func TestGlobMatcher(t *testing.T) {
+	header := []string{"latency/p99", "latency", "lat", "a*b", "Cost", "cost"}
+
+	for pattern, expected := range map[string][]bool{
+		"latency*":   {true, true, false, false, false, false},
+		"*/p??":      {true, false, false, false, false, false},
+		`a\*b`:       {false, false, false, true, false, false},
+		"[Cc]ost":    {false, false, false, false, true, true},
+		"[!c]ost":    {false, false, false, false, true, false},
+		"lat":        {false, false, true, false, false, false},
+		"*":          {true, true, true, true, true, true},
+		"latency/p9": {false, false, false, false, false, false},
+	} {
+		matched, _ := matchColumns([]ColumnMatcher{Glob(pattern)}, header)
+		assert.Equal(t, expected, matched, "Glob should match whole column names, slashes included: "+pattern)
+	}
+}
+
+func TestColumnMatcherErrors(t *testing.T) {
+	var cfg Config
+	cfg.ExcludeMatchers = []ColumnMatcher{Regexp("(unclosed"), Glob("[a-")}
+	cfg.IncludeMatchers = []ColumnMatcher{Indices("2-1"), Indices("x"), {Kind: 9}}
+
+	err := ValidateConfig(cfg)
+
+	assert.True(t, errors.Is(err, ErrInvalidPattern), "Invalid patterns should wrap ErrInvalidPattern")
+	assert.Len(t, strings.Split(err.Error(), "\n"), 5, "Every invalid pattern should be reported")
+	assert.Contains(t, err.Error(), "indices:2-1", "Invalid patterns should be named")
+}
+
+func TestParseIndexRanges(t *testing.T) {
+	ranges, err := parseIndexRanges("0-2, 5,7 - 9")
+
+	assert.Nil(t, err, "parseIndexRanges should not return a non-nil error")
+	assert.Equal(t, [][2]int{{0, 2}, {5, 5}, {7, 9}}, ranges, "Indices and ranges should be parsed in order")
+
+	for _, pattern := range []string{"", "-1", "3-", "a-b", "1,,2"} {
+		_, err := parseIndexRanges(pattern)
+		assert.NotNil(t, err, "Invalid ranges should return an error: "+pattern)
+	}
+}
+
+/* BENCHMARKS */
+// Generate a table with the given number of data lines
+func generateRecords(rows int) [][]string {
+	records := make([][]string, 0, rows+1)
+	records = append(records, []string{"ID", "Name", "Email", "Amount", "Status", "Comment"})
+	for i := range rows {
+		records = append(records, []string{
+			fmt.Sprint(i),
+			fmt.Sprintf("User %d", i%1000),
+			fmt.Sprintf("user%d@example.com", i),
+			fmt.Sprintf("%d.%02d", i%10000, i%100),
+			[]string{"active", "inactive", "pending"}[i%3],
+			"a | piped comment",
+		})
+	}
+
+	return records
+}
+
+// Rendering time per row should stay the same as the table grows. Compare the ns/row of the sub-benchmarks to check that rendering is linear
+func BenchmarkConvert(b *testing.B) {
+	for _, rows := range []int{25_000, 50_000, 100_000, 200_000} {
+		records := generateRecords(rows)
+
+		for _, compact := range []bool{false, true} {
+			var cfg Config
+			cfg.Align = Auto
+			cfg.Compact = compact
+			cfg.ExcludedColumns = []string{"Comment"}
+
+			b.Run(fmt.Sprintf("rows=%d/compact=%t", rows, compact), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := Convert(records, cfg); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*rows), "ns/row")
+			})
+		}
+	}
+}
+
+// Streaming rows should take constant time and memory per row
+func BenchmarkEncoderStreaming(b *testing.B) {
+	records := generateRecords(100_000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		encoder := NewEncoder(io.Discard, Config{Align: Left})
+		encoder.SetFixedWidth(20)
+
+		if err := encoder.WriteHeader(records[0]); err != nil {
+			b.Fatal(err)
+		}
+		for _, row := range records[1:] {
+			if err := encoder.WriteRow(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := encoder.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*100_000), "ns/row")
+}
+
+/* NON-MUTATING */
+// Deep copy of records, to check that they were not modified
+func cloneRecords(records [][]string) [][]string {
+	cloned := make([][]string, len(records))
+	for idx := range records {
+		cloned[idx] = slices.Clone(records[idx])
+	}
+
+	return cloned
+}
+
+func TestConvertDoesNotModifyRecords(t *testing.T) {
+	var cfg Config
+	cfg.Align = Left
+	cfg.SortRows = []RowSortKey{{Column: "Expression", Order: Descending}}
+
+	original := cloneRecords(dataStringWithPipeCharacters)
+
+	first, err := Convert(dataStringWithPipeCharacters, cfg)
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+
+	second, err := Convert(dataStringWithPipeCharacters, cfg)
+	assert.Nil(t, err, "Convert should not return a non-nil error")
+
+	assert.Equal(t, first, second, "Converting the same records twice should give the same table")
+	assert.NotContains(t, second, `\\|`, "Pipe characters should not be escaped twice")
+	assert.Equal(t, original, dataStringWithPipeCharacters, "Convert should not modify the records")
+}
+
+func TestConvertConcurrently(t *testing.T) {
+	records := generateRecords(200)
+	original := cloneRecords(records)
+
+	configs := []Config{
+		{Align: Auto},
+		{Compact: true, SortColumns: Descending},
+		{Renderer: "html", SortRows: []RowSortKey{{Column: "Amount", Order: Descending, Comparator: Numeric}}},
+		{Renderer: "terminal", ExcludedColumns: []string{"Email"}},
+	}
+
+	expected := make([]string, len(configs))
+	for idx, cfg := range configs {
+		var err error
+		expected[idx], err = Convert(records, cfg)
+		assert.Nil(t, err, "Convert should not return a non-nil error")
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		for idx, cfg := range configs {
+			wg.Go(func() {
+				res, err := Convert(records, cfg)
+				assert.Nil(t, err, "Convert should not return a non-nil error")
+				assert.Equal(t, expected[idx], res, STRINGS_SHOULD_BE_THE_SAME)
+			})
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, original, records, "Convert should not modify the records")
+}
@@ -0,0 +1,128 @@
+package mdtable
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// <!-- mdtable:start name=deps --> with an optional (quoted) name
+var startMarkerRegex = regexp.MustCompile(`<!--\s*mdtable:start(?:\s+name="?([^\s"]*)"?)?\s*-->`)
+
+// <!-- mdtable:end -->
+var endMarkerRegex = regexp.MustCompile(`<!--\s*mdtable:end\s*-->`)
+
+// Returned when a document does not contain a start marker with the requested name
+var ErrMarkerNotFound = errors.New("start marker was not found")
+
+// Convert the records into a Markdown table and put it between the <!-- mdtable:start name=<name> --> and <!-- mdtable:end --> markers of the document.
+// Everything between the markers is replaced while the markers themselves and the rest of the document are kept.
+// Every region with the given name is replaced. An empty name matches start markers without a name.
+func ReplaceBetweenMarkers(document string, name string, records [][]string, cfg Config) (string, error) {
+	regions, err := findMarkerRegions(document, name)
+	if err != nil {
+		return "", err
+	}
+
+	table, err := Convert(records, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// keep the line endings of the document
+	lineEnding := "\n"
+	if strings.Contains(document, "\r\n") {
+		lineEnding = "\r\n"
+	}
+
+	replacement := lineEnding
+	if table != "" {
+		replacement += strings.ReplaceAll(table, "\n", lineEnding) + lineEnding
+	}
+
+	var result strings.Builder
+	prevEnd := 0
+	for _, region := range regions {
+		result.WriteString(document[prevEnd:region[0]])
+		result.WriteString(replacement)
+		prevEnd = region[1]
+	}
+	result.WriteString(document[prevEnd:])
+
+	return result.String(), nil
+}
+
+// Find the byte ranges between the start markers with the given name and their end markers. Markers inside fenced code blocks are ignored
+func findMarkerRegions(document string, name string) ([][2]int, error) {
+	var regions [][2]int
+
+	codeBlocks := fencedCodeRanges(document)
+	inCodeBlock := func(pos int) bool {
+		return slices.ContainsFunc(codeBlocks, func(block [2]int) bool {
+			return pos >= block[0] && pos < block[1]
+		})
+	}
+
+	searchFrom := 0
+	for _, match := range startMarkerRegex.FindAllStringSubmatchIndex(document, -1) {
+		markerName := ""
+		if match[2] >= 0 {
+			markerName = document[match[2]:match[3]]
+		}
+
+		// skip markers with other names, examples in code blocks and start markers inside a region we already found
+		if markerName != name || inCodeBlock(match[0]) || match[0] < searchFrom {
+			continue
+		}
+
+		var endMatch []int
+		for _, candidate := range endMarkerRegex.FindAllStringIndex(document[match[1]:], -1) {
+			if !inCodeBlock(match[1] + candidate[0]) {
+				endMatch = candidate
+				break
+			}
+		}
+
+		if endMatch == nil {
+			return nil, fmt.Errorf("end marker for the start marker %q was not found", document[match[0]:match[1]])
+		}
+
+		regions = append(regions, [2]int{match[1], match[1] + endMatch[0]})
+		searchFrom = match[1] + endMatch[1]
+	}
+
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("%w: name=%s", ErrMarkerNotFound, name)
+	}
+
+	return regions, nil
+}
+
+// Get the byte ranges of the fenced code blocks of a document, fences included. A code block that is never closed runs until the end of the document
+func fencedCodeRanges(document string) [][2]int {
+	var ranges [][2]int
+
+	openFence := ""
+	blockStart, lineStart := 0, 0
+	for line := range strings.SplitAfterSeq(document, "\n") {
+		content := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case openFence == "":
+			openFence, blockStart = codeFence(content), lineStart
+		case closesCodeFence(openFence, content):
+			ranges = append(ranges, [2]int{blockStart, lineStart + len(line)})
+			openFence = ""
+		}
+
+		lineStart += len(line)
+	}
+
+	if openFence != "" {
+		ranges = append(ranges, [2]int{blockStart, len(document)})
+	}
+
+	return ranges
+}
@@ -0,0 +1,220 @@
+package mdtable
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Returned when the pattern of a ColumnMatcher can't be used
+var ErrInvalidPattern = errors.New("invalid column pattern")
+
+// How the pattern of a ColumnMatcher is compared to the header line
+type MatchKind int
+
+const (
+	// Header name equal to the pattern
+	MatchExact MatchKind = 0
+
+	// Header name equal to the pattern, ignoring case
+	MatchFold MatchKind = 1
+
+	// Header name matching a glob pattern such as "internal_*". '*' matches any run of characters, '?' a single character,
+	// '[a-z]' and '[!a-z]' a character class and '\' escapes the next character. Unlike file paths, '/' is an ordinary character
+	MatchGlob MatchKind = 2
+
+	// Header name matching a regular expression such as "_meta$", with the syntax of regexp
+	MatchRegexp MatchKind = 3
+
+	// Zero-based positions in the header line such as "0-2,5", made of single indices and inclusive ranges
+	MatchIndices MatchKind = 4
+)
+
+var matchKindName = map[MatchKind]string{
+	MatchExact:   "exact",
+	MatchFold:    "fold",
+	MatchGlob:    "glob",
+	MatchRegexp:  "regexp",
+	MatchIndices: "indices",
+}
+
+func (kind MatchKind) String() string {
+	return matchKindName[kind]
+}
+
+// Selects columns of the header line, either by name or by position
+type ColumnMatcher struct {
+	Kind    MatchKind
+	Pattern string
+}
+
+// Construct a matcher selecting the columns named exactly like name
+func ExactName(name string) ColumnMatcher {
+	return ColumnMatcher{Kind: MatchExact, Pattern: name}
+}
+
+// Construct a matcher selecting the columns named like name, ignoring case
+func FoldName(name string) ColumnMatcher {
+	return ColumnMatcher{Kind: MatchFold, Pattern: name}
+}
+
+// Construct a matcher selecting the columns whose name matches a glob pattern, e.g. "internal_*"
+func Glob(pattern string) ColumnMatcher {
+	return ColumnMatcher{Kind: MatchGlob, Pattern: pattern}
+}
+
+// Construct a matcher selecting the columns whose name matches a regular expression, e.g. "_meta$"
+func Regexp(pattern string) ColumnMatcher {
+	return ColumnMatcher{Kind: MatchRegexp, Pattern: pattern}
+}
+
+// Construct a matcher selecting the columns at the given zero-based positions, e.g. "0-2,5"
+func Indices(ranges string) ColumnMatcher {
+	return ColumnMatcher{Kind: MatchIndices, Pattern: ranges}
+}
+
+func (matcher ColumnMatcher) String() string {
+	return matcher.Kind.String() + ":" + matcher.Pattern
+}
+
+// Get the function telling whether the column at colIdx named colName is selected by the matcher
+func (matcher ColumnMatcher) compile() (func(colIdx int, colName string) bool, error) {
+	switch matcher.Kind {
+	case MatchExact:
+		return func(_ int, colName string) bool {
+			return colName == matcher.Pattern
+		}, nil
+	case MatchFold:
+		return func(_ int, colName string) bool {
+			return strings.EqualFold(colName, matcher.Pattern)
+		}, nil
+	case MatchGlob:
+		re, err := globRegexp(matcher.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidPattern, matcher, err)
+		}
+		return func(_ int, colName string) bool {
+			return re.MatchString(colName)
+		}, nil
+	case MatchRegexp:
+		re, err := regexp.Compile(matcher.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidPattern, matcher, err)
+		}
+		return func(_ int, colName string) bool {
+			return re.MatchString(colName)
+		}, nil
+	case MatchIndices:
+		ranges, err := parseIndexRanges(matcher.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidPattern, matcher, err)
+		}
+		return func(colIdx int, _ string) bool {
+			for _, r := range ranges {
+				if colIdx >= r[0] && colIdx <= r[1] {
+					return true
+				}
+			}
+			return false
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: match kind %d is out of range, please choose in range [0-4]", ErrInvalidPattern, matcher.Kind)
+	}
+}
+
+// Translate a glob pattern into an anchored regular expression
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	// column names may span several lines
+	var expr strings.Builder
+	expr.WriteString(`(?s)^`)
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			expr.WriteString(`.*`)
+		case '?':
+			expr.WriteString(`.`)
+		case '\\':
+			if i+1 == len(runes) {
+				return nil, errors.New("pattern ends with an unfinished escape")
+			}
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := slices.Index(runes[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("character class is not closed")
+			}
+
+			class := string(runes[i+1 : i+1+end])
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	expr.WriteString(`$`)
+	return regexp.Compile(expr.String())
+}
+
+// Parse comma-separated zero-based indices and inclusive ranges such as "0-2,5" into [first, last] pairs
+func parseIndexRanges(pattern string) ([][2]int, error) {
+	var ranges [][2]int
+
+	for item := range strings.SplitSeq(pattern, ",") {
+		item = strings.TrimSpace(item)
+		first, last, isRange := strings.Cut(item, "-")
+
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("%q is not a column index", item)
+		}
+
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || end < start {
+				return nil, fmt.Errorf("%q is not a range of column indices", item)
+			}
+		}
+
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	return ranges, nil
+}
+
+// Select the columns of the header line matched by any of the matchers. Also returns the matchers that didn't match any column
+func matchColumns(matchers []ColumnMatcher, headerLine []string) ([]bool, []ColumnMatcher) {
+	matched := make([]bool, len(headerLine))
+	var unmatched []ColumnMatcher
+
+	for _, matcher := range matchers {
+		// matchers were validated with the config
+		match, err := matcher.compile()
+		if err != nil {
+			continue
+		}
+
+		found := false
+		for colIdx, colName := range headerLine {
+			if match(colIdx, colName) {
+				matched[colIdx] = true
+				found = true
+			}
+		}
+
+		if !found {
+			unmatched = append(unmatched, matcher)
+		}
+	}
+
+	return matched, unmatched
+}
@@ -0,0 +1,198 @@
+package mdtable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// a single cell of a separator line, e.g. :---, ---: or :-:
+var separatorCellRegex = regexp.MustCompile(`^:?-+:?$`)
+
+// an HTML comment on its own line, as emitted for Config.Caption
+var captionRegex = regexp.MustCompile(`^<!--\s?(.*?)\s?-->$`)
+
+// Markdown table read back into records
+type ParsedTable struct {
+	// Caption of the table, read from an HTML comment on the line right above the header line
+	Caption string
+
+	// Header line followed by the data lines, with escaped pipe characters unescaped
+	Records [][]string
+
+	// Alignment of each column read from the separator line. Columns without colons are treated as Left
+	Align []Align
+
+	// Whether the separator line of each column contained a colon (internal)
+	explicitAlign []bool
+}
+
+// Parse the first Markdown table found in the text. Returns the records and alignment of the table and an error if no table was found.
+func Parse(markdown string) (ParsedTable, error) {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	for idx := range lines {
+		table, tableLen := parseTableAt(lines, idx)
+		if tableLen == 0 {
+			continue
+		}
+
+		// like GitHub, excess cells are ignored
+		for rowIdx, row := range table.Records {
+			table.Records[rowIdx] = fitRow(row, len(table.Records[0]))
+		}
+
+		if idx > 0 {
+			if match := captionRegex.FindStringSubmatch(strings.TrimSpace(lines[idx-1])); match != nil {
+				table.Caption = match[1]
+			}
+		}
+
+		return table, nil
+	}
+
+	return ParsedTable{}, errors.New("no Markdown table was found")
+}
+
+// Read Markdown from the reader and parse the first table found in it
+func ParseReader(r io.Reader) (ParsedTable, error) {
+	markdown, err := io.ReadAll(r)
+	if err != nil {
+		return ParsedTable{}, fmt.Errorf("failed to read Markdown: %w", err)
+	}
+
+	return Parse(string(markdown))
+}
+
+// Get the explicit alignment of each column keyed by header name, ready to be used as Config.ColumnAlign.
+// Columns whose separator had no colons are left out so that they fall back to Config.Align, as well as columns without alignment.
+func (table ParsedTable) ColumnAlign() map[string]Align {
+	columnAlign := map[string]Align{}
+	if len(table.Records) == 0 {
+		return columnAlign
+	}
+
+	// tables built by callers have no separator line, all of their alignments are explicit
+	fromSeparator := len(table.explicitAlign) == len(table.Align)
+
+	for colIdx, colName := range table.Records[0] {
+		if colIdx >= len(table.Align) {
+			break
+		}
+
+		if !fromSeparator || table.explicitAlign[colIdx] {
+			columnAlign[colName] = table.Align[colIdx]
+		}
+	}
+
+	return columnAlign
+}
+
+// Parse a table starting at the given line. Returns the table and the number of lines it spans, which is 0 if there is no table at that line.
+// Short data lines are padded with empty cells, long data lines keep all their cells.
+func parseTableAt(lines []string, start int) (ParsedTable, int) {
+	var table ParsedTable
+
+	if start+1 >= len(lines) || !isTableLine(lines[start]) {
+		return table, 0
+	}
+
+	header := splitTableLine(lines[start])
+	separator := splitTableLine(lines[start+1])
+	if len(header) != len(separator) {
+		return table, 0
+	}
+
+	for _, cell := range separator {
+		if !separatorCellRegex.MatchString(cell) {
+			return table, 0
+		}
+
+		table.Align = append(table.Align, parseSeparatorCell(cell))
+		table.explicitAlign = append(table.explicitAlign, strings.Contains(cell, ":"))
+	}
+
+	table.Records = append(table.Records, header)
+
+	end := start + 2
+	for ; end < len(lines) && isTableLine(lines[end]); end++ {
+		// missing cells are empty, excess cells are kept so that callers decide what to do with them
+		cells := splitTableLine(lines[end])
+		table.Records = append(table.Records, fitRow(cells, max(len(cells), len(header))))
+	}
+
+	return table, end - start
+}
+
+// Get the alignment described by a cell of the separator line
+func parseSeparatorCell(cell string) Align {
+	startsWithColon := strings.HasPrefix(cell, ":")
+	endsWithColon := strings.HasSuffix(cell, ":")
+
+	switch {
+	case startsWithColon && endsWithColon:
+		return Center
+	case endsWithColon:
+		return Right
+	default:
+		return Left
+	}
+}
+
+// check whether a line can be part of a table
+func isTableLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && strings.Contains(line, "|")
+}
+
+// Split a table line into trimmed cell values. Escaped pipe characters do not split cells and are unescaped,
+// as well as the backslashes doubled by the Markdown renderer right before a pipe or at the end of a cell.
+func splitTableLine(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+
+	var cells []string
+	var cell strings.Builder
+
+	// whether the last character was an unescaped pipe, closing the line
+	closed := false
+	for i := 0; i < len(line); i++ {
+		closed = false
+
+		switch line[i] {
+		case '\\':
+			run := line[i : len(line)-len(strings.TrimLeft(line[i:], `\`))]
+			rest := line[i+len(run):]
+			trailing := strings.TrimLeft(rest, " \t")
+			i += len(run) - 1
+
+			switch {
+			case strings.HasPrefix(rest, "|"):
+				// an odd number of backslashes escapes the pipe, e.g. \\\| is a backslash followed by a pipe
+				cell.WriteString(run[:len(run)/2])
+				if len(run)%2 == 1 {
+					cell.WriteByte('|')
+					i++
+				}
+			case len(run)%2 == 0 && (trailing == "" || trailing[0] == '|'):
+				cell.WriteString(run[:len(run)/2])
+			default:
+				cell.WriteString(run)
+			}
+		case '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			closed = true
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	if closed {
+		return cells
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
@@ -0,0 +1,115 @@
+package mdtable
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Wrapped by the ColumnError of a data line that doesn't fit the header line
+var ErrRaggedRow = errors.New("row does not fit the header line")
+
+// What to do with data lines that don't have as many fields as the header line
+type RaggedRowPolicy int
+
+const (
+	// Return an error naming the first data line that doesn't fit the header line
+	RaggedError RaggedRowPolicy = 0
+
+	// Pad short data lines with empty cells. Long data lines are still an error
+	RaggedPad RaggedRowPolicy = 1
+
+	// Pad short data lines with empty cells and drop the extra fields of long data lines
+	RaggedTruncate RaggedRowPolicy = 2
+
+	// Pad short data lines with empty cells and extend the header line with columns named "Column N" to fit the longest data line
+	RaggedExtendHeader RaggedRowPolicy = 3
+)
+
+var raggedRowPolicyName = map[RaggedRowPolicy]string{
+	RaggedError:        "Error",
+	RaggedPad:          "Pad",
+	RaggedTruncate:     "Truncate",
+	RaggedExtendHeader: "ExtendHeader",
+}
+
+func (policy RaggedRowPolicy) String() string {
+	return raggedRowPolicyName[policy]
+}
+
+// Make every data line as long as the header line according to the policy. Lines that need to change are copied, the records are not modified.
+// Returns the records unchanged if none of them is ragged
+func fitRaggedRows(records [][]string, policy RaggedRowPolicy) ([][]string, error) {
+	width := len(records[0])
+	longest := width
+	ragged := false
+
+	for rowIdx, line := range records[1:] {
+		if len(line) == width {
+			continue
+		}
+
+		ragged = true
+		longest = max(longest, len(line))
+
+		// the header line is row 0
+		if err := checkRaggedRow(line, width, rowIdx+1, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ragged {
+		return records, nil
+	}
+
+	fitted := make([][]string, len(records))
+	fitted[0] = records[0]
+
+	if policy == RaggedExtendHeader && longest > width {
+		width = longest
+		fitted[0] = extendHeader(records[0], width)
+	}
+
+	for rowIdx, line := range records[1:] {
+		fitted[rowIdx+1] = fitRow(line, width)
+	}
+
+	return fitted, nil
+}
+
+// Check whether a data line of the given row may be fitted to the width of the header line
+func checkRaggedRow(line []string, width int, rowIdx int, policy RaggedRowPolicy) error {
+	switch {
+	case len(line) < width && policy == RaggedError:
+		return &ColumnError{Row: rowIdx, Col: len(line), Err: fmt.Errorf("%w, it has %d fields but the header has %d", ErrRaggedRow, len(line), width)}
+	case len(line) > width && (policy == RaggedError || policy == RaggedPad):
+		return &ColumnError{Row: rowIdx, Col: width, Value: line[width], Err: fmt.Errorf("%w, it has %d fields but the header has %d", ErrRaggedRow, len(line), width)}
+	}
+
+	return nil
+}
+
+// Pad a line with empty cells or drop its extra fields so that it has exactly width fields
+func fitRow(line []string, width int) []string {
+	if len(line) == width {
+		return line
+	}
+
+	if len(line) > width {
+		return line[:width:width]
+	}
+
+	fitted := make([]string, width)
+	copy(fitted, line)
+	return fitted
+}
+
+// Extend the header line with columns named "Column N", N being the 1-based position of the column, up to width columns
+func extendHeader(header []string, width int) []string {
+	extended := slices.Grow(slices.Clone(header), width-len(header))
+	for colIdx := len(header); colIdx < width; colIdx++ {
+		extended = append(extended, fmt.Sprintf("Column %d", colIdx+1))
+	}
+
+	return extended
+}
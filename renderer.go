@@ -0,0 +1,250 @@
+package mdtable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Name of the renderer used when Config.Renderer is not set
+const DefaultRenderer = "markdown"
+
+// Output format of a table. Convert calls Escape for every cell first, then Header once, Separator once, Row for every data line and finally Footer.
+// Hooks write whole lines terminated by "\n"; Convert removes the final line break from the result.
+type Renderer interface {
+	// Escape a cell value for the output format, e.g. pipe characters for Markdown. Column widths are measured on escaped values
+	Escape(value string) string
+
+	// Write everything before the data lines, usually the caption and the header line
+	Header(w io.Writer, layout TableLayout) error
+
+	// Write what goes between the header line and the data lines
+	Separator(w io.Writer, layout TableLayout) error
+
+	// Write a data line. rowIdx starts at 0 for the first data line
+	Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error
+
+	// Write everything after the data lines. layout.Rows is the number of data lines that were written
+	Footer(w io.Writer, layout TableLayout) error
+}
+
+// Everything a Renderer needs to know about the table. Only included columns are present, in the order they are rendered
+type TableLayout struct {
+	// Caption of the table, empty if there is none
+	Caption string
+
+	// Whether the compact version of the table was requested
+	Compact bool
+
+	// Escaped header line
+	Header []string
+
+	// Width of each column in monospace cells, the display width of the widest escaped value of the column
+	Widths []int
+
+	// Alignment of each column, Auto is already resolved to Left, Right or Center
+	Align []Align
+
+	// Number of data lines
+	Rows int
+
+	// Whether the only data line is the placeholder of a header-only table. The placeholder is its first cell, the other cells are empty
+	Placeholder bool
+
+	// Border style of the terminal renderer
+	BorderStyle BorderStyle
+
+	// Colors of the terminal renderer, empty if not colored
+	HeaderColor, CellColor, BorderColor Color
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		DefaultRenderer: markdownRenderer{},
+		"html":          htmlRenderer{},
+		"rst-grid":      rstGridRenderer{},
+		"rst-simple":    rstSimpleRenderer{},
+		"asciidoc":      asciiDocRenderer{},
+		"org":           orgRenderer{},
+		"mediawiki":     mediaWikiRenderer{},
+		"jira":          jiraRenderer{},
+		"latex":         latexRenderer{},
+		"terminal":      terminalRenderer{},
+	}
+)
+
+// Register a renderer under the given name so that it can be selected with Config.Renderer. Registering an existing name replaces its renderer.
+func RegisterRenderer(name string, renderer Renderer) error {
+	if name == "" {
+		return errors.New("renderer name must not be empty")
+	}
+
+	if renderer == nil {
+		return fmt.Errorf("renderer %q must not be nil", name)
+	}
+
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[name] = renderer
+
+	return nil
+}
+
+// Get the names of all registered renderers, sorted alphabetically
+func RendererNames() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Get a registered renderer. An empty name falls back to DefaultRenderer
+func lookupRenderer(name string) (Renderer, bool) {
+	if name == "" {
+		name = DefaultRenderer
+	}
+
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	renderer, ok := renderers[name]
+
+	return renderer, ok
+}
+
+// Render the records with the renderer selected in the config. Warnings and statistics of the table are added to the report
+func render(w io.Writer, records [][]string, cfg Config, report *Report) error {
+
+	cfg, records, allExcluded, err := prepareConversion(records, cfg, report)
+
+	if err != nil || allExcluded {
+		return err
+	}
+
+	renderer, _ := lookupRenderer(cfg.Renderer)
+
+	// keep only the included columns, in their final order, and escape them for the output format
+	visibleColumns := getVisibleColumnsIndices(cfg)
+	lines := make([][]string, len(records))
+	for lineIdx, line := range records {
+		lines[lineIdx] = escapeLine(renderer, line, visibleColumns)
+	}
+
+	// header-only tables get a placeholder data line so that readers can tell the table is empty on purpose
+	if len(lines) == 1 && cfg.EmptyPlaceholder != "" {
+		lines = append(lines, placeholderLine(renderer, cfg.EmptyPlaceholder, len(lines[0])))
+	}
+
+	layout := newTableLayout(cfg, lines[0])
+	layout.Rows = len(lines) - 1
+	layout.Placeholder = len(records) == 1 && layout.Rows == 1
+
+	// max length of each column so we can beautify the table. Auto aligned columns are resolved in the same pass
+	layout.Widths, layout.Align = getMaxColumnLengths(lines, layout.Align)
+	cfg.debug("Computed column widths", "columns", layout.Header, "widths", layout.Widths, "align", layout.Align)
+
+	report.Rows = len(records) - 1
+	report.Widths = renderedWidths(renderer, layout)
+	report.Columns = make([]string, len(visibleColumns))
+	for colIdx, i := range visibleColumns {
+		report.Columns[colIdx] = records[0][i]
+	}
+
+	if err := renderer.Header(w, layout); err != nil {
+		return err
+	}
+
+	if err := renderer.Separator(w, layout); err != nil {
+		return err
+	}
+
+	for rowIdx, line := range lines[1:] {
+		if err := renderer.Row(w, layout, line, rowIdx); err != nil {
+			return err
+		}
+	}
+
+	return renderer.Footer(w, layout)
+}
+
+// Implemented by built-in renderers that draw some columns wider than their widest value
+type widthAdjuster interface {
+	// Get the layout with the widths the columns are actually drawn with
+	adjustLayout(layout TableLayout) TableLayout
+}
+
+// Get the widths the renderer draws the columns with
+func renderedWidths(renderer Renderer, layout TableLayout) []int {
+	if adjuster, ok := renderer.(widthAdjuster); ok {
+		return adjuster.adjustLayout(layout).Widths
+	}
+
+	return layout.Widths
+}
+
+// Create the layout of a table with the given escaped header line. Widths and the number of data lines are left for the caller to fill in
+func newTableLayout(cfg Config, header []string) TableLayout {
+	visibleColumns := getVisibleColumnsIndices(cfg)
+
+	aligns := make([]Align, len(visibleColumns))
+	for colIdx, i := range visibleColumns {
+		aligns[colIdx] = cfg.columnAligns[i]
+	}
+
+	return TableLayout{
+		Caption: cfg.Caption,
+		Compact: cfg.Compact,
+		Header:  header,
+		Align:   aligns,
+
+		BorderStyle: cfg.BorderStyle,
+		HeaderColor: cfg.HeaderColor,
+		CellColor:   cfg.CellColor,
+		BorderColor: cfg.BorderColor,
+	}
+}
+
+// Create the data line of a header-only table, the escaped placeholder followed by empty cells
+func placeholderLine(renderer Renderer, placeholder string, columns int) []string {
+	line := make([]string, columns)
+	line[0] = renderer.Escape(placeholder)
+
+	return line
+}
+
+// Keep only the included columns of a line, in their final order, and escape them for the output format
+func escapeLine(renderer Renderer, line []string, visibleColumns []int) []string {
+	escaped := make([]string, len(visibleColumns))
+	for colIdx, i := range visibleColumns {
+		escaped[colIdx] = renderer.Escape(line[i])
+	}
+
+	return escaped
+}
+
+// Get the indices of the included columns in the order they are rendered
+func getVisibleColumnsIndices(cfg Config) []int {
+	// columnAligns has one entry per column of the header line, orderedColumnsIndices may only hold some of them
+	excluded := make([]bool, len(cfg.columnAligns))
+	for _, i := range cfg.excludedColumnsIndices {
+		excluded[i] = true
+	}
+
+	visibleColumns := make([]int, 0, len(cfg.orderedColumnsIndices))
+	for _, i := range cfg.orderedColumnsIndices {
+		// If current column is excluded, ignore it
+		if !excluded[i] {
+			visibleColumns = append(visibleColumns, i)
+		}
+	}
+
+	return visibleColumns
+}
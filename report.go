@@ -0,0 +1,111 @@
+package mdtable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind of a warning, stable so that callers can react to specific warnings
+type WarningCode string
+
+const (
+	// A row sort key has both Comparator and SortFunction set, Comparator is ignored
+	WarnComparatorIgnored WarningCode = "comparator-ignored"
+
+	// SortComparator is set but SortColumns is neither Ascending nor Descending
+	WarnSortComparatorIgnored WarningCode = "sort-comparator-ignored"
+
+	// SortFunction is set but SortColumns is not Custom
+	WarnSortFunctionIgnored WarningCode = "sort-function-ignored"
+
+	// SortColumns is set but ColumnOrder already defines the order of the columns
+	WarnSortColumnsIgnored WarningCode = "sort-columns-ignored"
+
+	// A column listed in ExcludedColumns does not exist in the header line
+	WarnExcludedColumnNotFound WarningCode = "excluded-column-not-found"
+
+	// A pattern of ExcludeMatchers or IncludeMatchers does not match any column of the header line
+	WarnPatternNotMatched WarningCode = "pattern-not-matched"
+
+	// Every column was excluded, nothing was rendered
+	WarnAllColumnsExcluded WarningCode = "all-columns-excluded"
+)
+
+// Something that didn't stop the conversion but probably isn't what the caller wanted
+type Warning struct {
+	Code WarningCode
+
+	// Human readable description of the warning
+	Message string
+
+	// Name of the column the warning is about, empty if it's about the whole table
+	Column string
+}
+
+func (warning Warning) String() string {
+	return fmt.Sprintf("%s: %s", warning.Code, warning.Message)
+}
+
+// Outcome of a conversion, returned by ConvertWithReport
+type Report struct {
+	// Warnings raised while validating the config and converting the records
+	Warnings []Warning
+
+	// Number of data lines rendered, not counting the header line nor the placeholder of a header-only table
+	Rows int
+
+	// Names of the rendered columns, in the order they are rendered
+	Columns []string
+
+	// Names of the columns that were not rendered, in the order of the header line
+	DroppedColumns []string
+
+	// Width of each rendered column in monospace cells as drawn by the renderer: the display width of its widest escaped value,
+	// widened where the output format requires it, e.g. to hold the alignment colons of a Markdown separator
+	Widths []int
+
+	// Display width of the longest line of the output
+	Width int
+}
+
+// Whether the report contains a warning with the given code
+func (report Report) HasWarning(code WarningCode) bool {
+	for _, warning := range report.Warnings {
+		if warning.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Convert records like Convert does and report the warnings raised along the way together with some statistics of the output.
+// Warnings are logged as well.
+func ConvertWithReport(records [][]string, cfg Config) (string, Report, error) {
+
+	var result strings.Builder
+	var report Report
+
+	if err := render(&result, records, cfg, &report); err != nil {
+		return "", report, err
+	}
+
+	table := strings.TrimSuffix(result.String(), "\n")
+
+	for line := range strings.SplitSeq(table, "\n") {
+		report.Width = max(report.Width, displayWidth(line))
+	}
+
+	return table, report, nil
+}
+
+// Add a warning to the report and log it
+func (report *Report) warn(cfg Config, warning Warning) {
+	report.Warnings = append(report.Warnings, warning)
+
+	if warning.Column != "" {
+		cfg.logger().Warn(warning.Message, "code", warning.Code, "column", warning.Column)
+	} else {
+		cfg.logger().Warn(warning.Message, "code", warning.Code)
+	}
+}
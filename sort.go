@@ -0,0 +1,333 @@
+package mdtable
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sort the data lines by the given keys. The header line is kept on top and lines with equal keys keep their original order.
+// The caller's slice is not reordered, a new slice of lines is returned instead.
+func sortRows(records [][]string, keys []RowSortKey) [][]string {
+	if len(keys) == 0 || len(records) < 3 {
+		return records
+	}
+
+	sortedRecords := make([][]string, len(records))
+	copy(sortedRecords, records)
+
+	// resolve the column index and compare function of every key once, rather than for every comparison
+	colIndices := make([]int, len(keys))
+	compareFuncs := make([]ColumnSortFunction, len(keys))
+	for i, key := range keys {
+		colIndices[i] = slices.Index(records[0], key.Column)
+		compareFuncs[i] = key.SortFunction
+		if compareFuncs[i] == nil {
+			compareFuncs[i], _ = comparatorFunction(key.Comparator)
+		}
+	}
+
+	slices.SortStableFunc(sortedRecords[1:], func(a, b []string) int {
+		for i, key := range keys {
+			res := compareFuncs[i](a[colIndices[i]], b[colIndices[i]])
+			if key.Order == Descending {
+				res = -res
+			}
+
+			if res != 0 {
+				return res
+			}
+		}
+
+		return 0
+	})
+
+	return sortedRecords
+}
+
+type Comparator string
+
+const (
+	Lexical  Comparator = "lexical"
+	Numeric  Comparator = "numeric"
+	Natural  Comparator = "natural"
+	SemVer   Comparator = "semver"
+	DateTime Comparator = "datetime"
+	Duration Comparator = "duration"
+)
+
+var comparators = map[Comparator]ColumnSortFunction{
+	Lexical:  CompareLexical,
+	Numeric:  CompareNumeric,
+	Natural:  CompareNatural,
+	SemVer:   CompareSemVer,
+	DateTime: CompareDateTime,
+	Duration: CompareDuration,
+}
+
+// layouts accepted by CompareDateTime, tried in order
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Get the compare function of a built-in comparator. An empty name falls back to Lexical
+func comparatorFunction(name Comparator) (ColumnSortFunction, bool) {
+	if name == "" {
+		return CompareLexical, true
+	}
+
+	compareFunc, ok := comparators[name]
+	return compareFunc, ok
+}
+
+// Compare two strings ignoring case
+func CompareLexical(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// Compare numbers, percentages and currency amounts such as 1,200, 12.5% or $3.
+// Values that are not numbers are placed after numbers and compared lexically.
+func CompareNumeric(a, b string) int {
+	return compareParsed(a, b, parseNumber, cmp.Compare[float64])
+}
+
+// Compare strings the way a human would, treating runs of digits as numbers. "file9" comes before "file10".
+func CompareNatural(a, b string) int {
+	aChunks, bChunks := splitNatural(a), splitNatural(b)
+
+	for i := 0; i < len(aChunks) && i < len(bChunks); i++ {
+		aIsNum, bIsNum := isDigit(aChunks[i][0]), isDigit(bChunks[i][0])
+
+		res := 0
+		if aIsNum && bIsNum {
+			res = compareDigits(aChunks[i], bChunks[i])
+		} else {
+			res = CompareLexical(aChunks[i], bChunks[i])
+		}
+
+		if res != 0 {
+			return res
+		}
+	}
+
+	return cmp.Compare(len(aChunks), len(bChunks))
+}
+
+// Compare semantic versions such as v1.9.0 and 1.10.0-rc.1. Missing minor and patch numbers count as 0 and build metadata is ignored.
+// Values that are not versions are placed after versions and compared lexically.
+func CompareSemVer(a, b string) int {
+	return compareParsed(a, b, parseSemVer, compareSemVer)
+}
+
+// Compare ISO 8601 dates and timestamps such as 2024-01-31 or 2024-01-31T08:00:00Z.
+// Values that are not dates are placed after dates and compared lexically.
+func CompareDateTime(a, b string) int {
+	return compareParsed(a, b, parseDateTime, func(x, y time.Time) int {
+		return x.Compare(y)
+	})
+}
+
+// Compare durations such as 1h30m, 250ms or 01:30:00.
+// Values that are not durations are placed after durations and compared lexically.
+func CompareDuration(a, b string) int {
+	return compareParsed(a, b, parseDuration, cmp.Compare[time.Duration])
+}
+
+// Compare two strings by parsing them first. Parsed values come before values that failed to parse.
+func compareParsed[T any](a, b string, parse func(string) (T, bool), compare func(T, T) int) int {
+	aVal, aOk := parse(a)
+	bVal, bOk := parse(b)
+
+	switch {
+	case aOk && bOk:
+		return compare(aVal, bVal)
+	case aOk:
+		return -1
+	case bOk:
+		return 1
+	default:
+		return CompareLexical(a, b)
+	}
+}
+
+// parse a number, ignoring currency symbols, percent signs and thousands separators
+func parseNumber(s string) (float64, bool) {
+	if !isNumeric(s) {
+		return 0, false
+	}
+
+	match := numericRegex.FindStringSubmatch(strings.TrimSpace(s))
+	negative := match[1] == "-" || match[2] == "-"
+	s = strings.Map(func(r rune) rune {
+		if isDigit(byte(r)) || r == '.' {
+			return r
+		}
+		return -1
+	}, s)
+
+	num, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if negative {
+		num = -num
+	}
+
+	return num, true
+}
+
+type semVer struct {
+	core       [3]int
+	preRelease []string
+}
+
+// parse a semantic version with an optional "v" prefix
+func parseSemVer(s string) (semVer, bool) {
+	var version semVer
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, preRelease, hasPreRelease := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version, false
+	}
+
+	for i, part := range parts {
+		num, err := strconv.Atoi(part)
+		if err != nil || num < 0 {
+			return version, false
+		}
+		version.core[i] = num
+	}
+
+	if hasPreRelease {
+		version.preRelease = strings.Split(preRelease, ".")
+	}
+
+	return version, true
+}
+
+// compare two semantic versions following the precedence rules of semver.org
+func compareSemVer(a, b semVer) int {
+	if res := slices.Compare(a.core[:], b.core[:]); res != 0 {
+		return res
+	}
+
+	// a version without pre-release identifiers has a higher precedence
+	switch {
+	case len(a.preRelease) == 0 && len(b.preRelease) == 0:
+		return 0
+	case len(a.preRelease) == 0:
+		return 1
+	case len(b.preRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.preRelease) && i < len(b.preRelease); i++ {
+		aNum, aErr := strconv.Atoi(a.preRelease[i])
+		bNum, bErr := strconv.Atoi(b.preRelease[i])
+
+		res := 0
+		switch {
+		case aErr == nil && bErr == nil:
+			res = cmp.Compare(aNum, bNum)
+		case aErr == nil:
+			// numeric identifiers have a lower precedence than alphanumeric ones
+			res = -1
+		case bErr == nil:
+			res = 1
+		default:
+			res = strings.Compare(a.preRelease[i], b.preRelease[i])
+		}
+
+		if res != 0 {
+			return res
+		}
+	}
+
+	return cmp.Compare(len(a.preRelease), len(b.preRelease))
+}
+
+// parse an ISO 8601 date or timestamp
+func parseDateTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parse a Go duration (1h30m) or a clock duration (01:30:00 or 01:30)
+func parseDuration(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, true
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+
+	var d time.Duration
+	for _, part := range parts {
+		num, err := strconv.Atoi(part)
+		if err != nil || num < 0 {
+			return 0, false
+		}
+		d = d*60 + time.Duration(num)
+	}
+
+	// hh:mm is counted in minutes, hh:mm:ss in seconds
+	if len(parts) == 2 {
+		return d * time.Minute, true
+	}
+
+	return d * time.Second, true
+}
+
+// split a string into chunks of digits and non-digits
+func splitNatural(s string) []string {
+	var chunks []string
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || isDigit(s[i]) != isDigit(s[start]) {
+			chunks = append(chunks, s[start:i])
+			start = i
+		}
+	}
+
+	return chunks
+}
+
+// compare two strings of digits by their numeric value without overflowing
+func compareDigits(a, b string) int {
+	trimmedA, trimmedB := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if res := cmp.Compare(len(trimmedA), len(trimmedB)); res != 0 {
+		return res
+	}
+
+	if res := strings.Compare(trimmedA, trimmedB); res != 0 {
+		return res
+	}
+
+	// same value, fewer leading zeros first
+	return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -2,83 +2,72 @@ package mdtable
 
 import (
 	"errors"
+	"regexp"
+	"slices"
 	"strings"
-	"unicode/utf8"
 )
 
-const padLengthErrorString = "the length of the original string already exceeded desired length"
+// Returned when a value is wider than the column it's padded to
+var ErrValueTooWide = errors.New("the length of the original string already exceeded desired length")
+
+// integers, decimals, thousands separators, percentages and currency amounts such as -1,234.50, 12%, $5 or 5 €
+// with a single sign, either before the currency symbol (group 1) or right after it (group 2)
+var numericRegex = regexp.MustCompile(`^(?:([+-])?[$€£¥₹]?\s?|[$€£¥₹]\s?([+-]))(\d{1,3}(,\d{3})+|\d+)?(\.\d+)?\s?[%$€£¥₹]?$`)
+
+// values that are commonly used in boolean or flag columns
+var flagValues = []string{"true", "false", "yes", "no", "y", "n", "on", "off", "x", "✓", "✔", "✗", "✘"}
 
 // pad characters to start of a string
 func padStart(originalString string, desiredLen int, paddingChar rune) (string, error) {
-	if utf8.RuneCountInString(originalString) > desiredLen {
-		return "", errors.New(padLengthErrorString)
-	}
+	lenDiff := desiredLen - displayWidth(originalString)
 
-	lenDiff := desiredLen - utf8.RuneCountInString(originalString)
+	if lenDiff < 0 {
+		return "", ErrValueTooWide
+	}
 
 	if lenDiff == 0 {
 		return originalString, nil
 	}
 
-	preFix := ""
-
-	for range lenDiff {
-		preFix += string(paddingChar)
-	}
-
-	return preFix + originalString, nil
+	return strings.Repeat(string(paddingChar), lenDiff) + originalString, nil
 }
 
 // pad characters to the end of a string
 func padEnd(originalString string, desiredLen int, paddingChar rune) (string, error) {
-	if utf8.RuneCountInString(originalString) > desiredLen {
-		return "", errors.New(padLengthErrorString)
-	}
+	lenDiff := desiredLen - displayWidth(originalString)
 
-	lenDiff := desiredLen - utf8.RuneCountInString(originalString)
+	if lenDiff < 0 {
+		return "", ErrValueTooWide
+	}
 
 	if lenDiff == 0 {
 		return originalString, nil
 	}
 
-	postFix := ""
-
-	for range lenDiff {
-		postFix += string(paddingChar)
-	}
-
-	return originalString + postFix, nil
+	return originalString + strings.Repeat(string(paddingChar), lenDiff), nil
 }
 
-// Pad both sides. If odd characters are to be padded, the longer string is padded to the start of the string.
+// Pad both sides. If odd characters are to be padded, the extra character is padded to the end of the string.
 func padCenter(originalString string, desiredLen int, paddingChar rune) (string, error) {
-	if utf8.RuneCountInString(originalString) > desiredLen {
-		return "", errors.New(padLengthErrorString)
-	}
+	lenDiff := desiredLen - displayWidth(originalString)
 
-	lenDiff := desiredLen - utf8.RuneCountInString(originalString)
+	if lenDiff < 0 {
+		return "", ErrValueTooWide
+	}
 
 	toPadStart := lenDiff / 2
 	toPadEnd := lenDiff - toPadStart
 
-	resStr := originalString
-	resStr, err := padEnd(originalString, utf8.RuneCountInString(resStr)+toPadEnd, paddingChar)
-	if err != nil {
-		return "", err
-	}
-
-	resStr, err = padStart(resStr, utf8.RuneCountInString(resStr)+toPadStart, paddingChar)
-	if err != nil {
-		return "", err
-	}
-
-	return resStr, nil
+	return strings.Repeat(string(paddingChar), toPadStart) + originalString + strings.Repeat(string(paddingChar), toPadEnd), nil
 }
 
-func replaceAllInSlice(slice []string, oldString string, newString string) []string {
-	for idx := range slice {
-		slice[idx] = strings.ReplaceAll(slice[idx], oldString, newString)
-	}
+// check whether a string is a number, percentage or currency amount
+func isNumeric(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.ContainsAny(s, "0123456789") && numericRegex.MatchString(s)
+}
 
-	return slice
-}
\ No newline at end of file
+// check whether a string is a boolean or flag value
+func isFlag(s string) bool {
+	return slices.Contains(flagValues, strings.ToLower(strings.TrimSpace(s)))
+}
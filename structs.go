@@ -0,0 +1,212 @@
+package mdtable
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var alignTagValues = map[string]Align{
+	"center": Center,
+	"left":   Left,
+	"right":  Right,
+	"auto":   Auto,
+}
+
+// Exported field of a struct that becomes a column
+type structColumn struct {
+	name  string
+	index []int
+	align Align
+
+	// whether the tag set an alignment for the column
+	hasAlign bool
+}
+
+// Convert a slice of structs (or pointers to structs) into a markdown table. Every exported field becomes a column, fields of embedded structs included.
+// Columns are configured with the mdtable struct tag: `mdtable:"Display Name,align=right"` renames and aligns a column while `mdtable:"-"` or
+// `mdtable:",omit"` leaves the field out, along with the fields it promotes. Values implementing fmt.Stringer or encoding.TextMarshaler are formatted with them, nil pointers are empty cells.
+// Alignments set in Config.ColumnAlign take precedence over the ones from struct tags.
+func ConvertStructs[T any](items []T, cfg Config) (string, error) {
+	columns, err := getStructColumns(reflect.TypeFor[T]())
+	if err != nil {
+		return "", err
+	}
+
+	records := make([][]string, 0, len(items)+1)
+
+	header := make([]string, len(columns))
+	columnAlign := map[string]Align{}
+	for colIdx, col := range columns {
+		header[colIdx] = col.name
+		if col.hasAlign {
+			columnAlign[col.name] = col.align
+		}
+	}
+	records = append(records, header)
+
+	for _, item := range items {
+		records = append(records, getStructLine(reflect.ValueOf(&item).Elem(), columns))
+	}
+
+	maps.Copy(columnAlign, cfg.ColumnAlign)
+	cfg.ColumnAlign = columnAlign
+
+	return Convert(records, cfg)
+}
+
+// Get the columns of a struct type from its exported fields and their tags
+func getStructColumns(structType reflect.Type) ([]structColumn, error) {
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ConvertStructs requires a struct type, received %s", structType)
+	}
+
+	var columns []structColumn
+
+	// index of the fields left out, whose promoted fields are left out as well
+	var omitted [][]int
+	isPromotedFromOmitted := func(field reflect.StructField) bool {
+		return slices.ContainsFunc(omitted, func(index []int) bool {
+			return len(field.Index) > len(index) && slices.Equal(field.Index[:len(index)], index)
+		})
+	}
+
+	for _, field := range reflect.VisibleFields(structType) {
+		if isPromotedFromOmitted(field) {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("mdtable")
+		if isOmitTag(tag) {
+			omitted = append(omitted, field.Index)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		// fields of embedded structs are promoted, the embedded struct itself is not a column unless it's tagged
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && !hasTag {
+			continue
+		}
+
+		col := structColumn{name: field.Name, index: field.Index}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name != "" {
+			col.name = name
+		}
+
+		for option := range strings.SplitSeq(options, ",") {
+			option = strings.TrimSpace(option)
+			switch {
+			case option == "":
+			case strings.HasPrefix(option, "align="):
+				align, ok := alignTagValues[strings.ToLower(strings.TrimPrefix(option, "align="))]
+				if !ok {
+					return nil, fmt.Errorf("field %s has an unknown alignment in its mdtable tag: %q", field.Name, option)
+				}
+				col.align = align
+				col.hasAlign = true
+			default:
+				return nil, fmt.Errorf("field %s has an unknown option in its mdtable tag: %q", field.Name, option)
+			}
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, nil
+}
+
+// Check whether a mdtable tag leaves its field out
+func isOmitTag(tag string) bool {
+	if tag == "-" {
+		return true
+	}
+
+	_, options, _ := strings.Cut(tag, ",")
+	for option := range strings.SplitSeq(options, ",") {
+		if strings.TrimSpace(option) == "omit" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get the cell values of a struct (or pointer to a struct) for the given columns
+func getStructLine(item reflect.Value, columns []structColumn) []string {
+	line := make([]string, len(columns))
+
+	for item.Kind() == reflect.Pointer {
+		if item.IsNil() {
+			return line
+		}
+		item = item.Elem()
+	}
+
+	for colIdx, col := range columns {
+		// fails when going through a nil embedded pointer, which leaves the cell empty
+		field, err := item.FieldByIndexErr(col.index)
+		if err == nil {
+			line[colIdx] = formatStructValue(field)
+		}
+	}
+
+	return line
+}
+
+// Format a field value as a cell. fmt.Stringer is preferred over encoding.TextMarshaler, which is preferred over the default format.
+func formatStructValue(value reflect.Value) string {
+	for {
+		if !value.IsValid() {
+			return ""
+		}
+
+		if (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil() {
+			return ""
+		}
+
+		// values promoted through unexported embedded structs can't be passed to their methods
+		if !value.CanInterface() {
+			return fmt.Sprint(value)
+		}
+
+		// methods with pointer receivers need an addressable value
+		if !value.CanAddr() {
+			addressable := reflect.New(value.Type()).Elem()
+			addressable.Set(value)
+			value = addressable
+		}
+
+		for _, candidate := range []reflect.Value{value, value.Addr()} {
+			switch formatter := candidate.Interface().(type) {
+			case fmt.Stringer:
+				return formatter.String()
+			case encoding.TextMarshaler:
+				if text, err := formatter.MarshalText(); err == nil {
+					return string(text)
+				}
+			}
+		}
+
+		if value.Kind() != reflect.Pointer && value.Kind() != reflect.Interface {
+			return fmt.Sprint(value.Interface())
+		}
+
+		value = value.Elem()
+	}
+}
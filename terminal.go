@@ -0,0 +1,139 @@
+package mdtable
+
+import (
+	"io"
+	"strings"
+)
+
+type BorderStyle int
+
+const (
+	Single  BorderStyle = 0
+	Double  BorderStyle = 1
+	Rounded BorderStyle = 2
+	ASCII   BorderStyle = 3
+)
+
+// SGR parameters of an ANSI escape sequence, e.g. "1" for bold or "1;36" for bold cyan. Constants can be combined with Color(Bold + ";" + Cyan)
+type Color string
+
+const (
+	Bold    Color = "1"
+	Dim     Color = "2"
+	Red     Color = "31"
+	Green   Color = "32"
+	Yellow  Color = "33"
+	Blue    Color = "34"
+	Magenta Color = "35"
+	Cyan    Color = "36"
+	White   Color = "37"
+)
+
+// Characters drawing the borders of a terminal table
+type boxChars struct {
+	horizontal, vertical                  string
+	topLeft, topMiddle, topRight          string
+	middleLeft, middleMiddle, middleRight string
+	bottomLeft, bottomMiddle, bottomRight string
+}
+
+var borderStyles = map[BorderStyle]boxChars{
+	Single:  {"─", "│", "┌", "┬", "┐", "├", "┼", "┤", "└", "┴", "┘"},
+	Double:  {"═", "║", "╔", "╦", "╗", "╠", "╬", "╣", "╚", "╩", "╝"},
+	Rounded: {"─", "│", "╭", "┬", "╮", "├", "┼", "┤", "╰", "┴", "╯"},
+	ASCII:   {"-", "|", "+", "+", "+", "+", "+", "+", "+", "+", "+"},
+}
+
+// Replaces characters that would break the borders of a terminal table
+var terminalEscaper = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// Renderer of tables for terminals, drawn with box-drawing characters and optionally colored with ANSI escape sequences.
+// ANSI escape sequences already present in cells don't count towards the width of their column.
+type terminalRenderer struct{}
+
+func (terminalRenderer) Escape(value string) string {
+	return terminalEscaper.Replace(value)
+}
+
+func (terminalRenderer) Header(w io.Writer, layout TableLayout) error {
+	lines := []string{}
+	if layout.Caption != "" {
+		lines = append(lines, layout.Caption)
+	}
+
+	box := borderStyles[layout.BorderStyle]
+	header, err := terminalRow(layout, layout.Header, layout.HeaderColor, 0)
+	if err != nil {
+		return err
+	}
+
+	lines = append(lines, terminalBorder(layout, box.topLeft, box.topMiddle, box.topRight), header)
+
+	return writeLines(w, "", lines...)
+}
+
+func (terminalRenderer) Separator(w io.Writer, layout TableLayout) error {
+	box := borderStyles[layout.BorderStyle]
+	return writeLines(w, "", terminalBorder(layout, box.middleLeft, box.middleMiddle, box.middleRight))
+}
+
+func (terminalRenderer) Row(w io.Writer, layout TableLayout, cells []string, rowIdx int) error {
+	row, err := terminalRow(layout, cells, layout.CellColor, rowIdx+1)
+	if err != nil {
+		return err
+	}
+
+	return writeLines(w, "", row)
+}
+
+func (terminalRenderer) Footer(w io.Writer, layout TableLayout) error {
+	box := borderStyles[layout.BorderStyle]
+	return writeLines(w, "", terminalBorder(layout, box.bottomLeft, box.bottomMiddle, box.bottomRight))
+}
+
+// Get a horizontal border line with the given corner and junction characters
+func terminalBorder(layout TableLayout, left string, middle string, right string) string {
+	box := borderStyles[layout.BorderStyle]
+
+	// cells are surrounded by a space on both sides unless the table is compact
+	cellPadding := 2
+	if layout.Compact {
+		cellPadding = 0
+	}
+
+	segments := make([]string, len(layout.Widths))
+	for i, width := range layout.Widths {
+		segments[i] = strings.Repeat(box.horizontal, width+cellPadding)
+	}
+
+	return colorize(left+strings.Join(segments, middle)+right, layout.BorderColor)
+}
+
+// Get a line of padded and colored cells separated by vertical borders
+func terminalRow(layout TableLayout, cells []string, color Color, lineIdx int) (string, error) {
+	box := borderStyles[layout.BorderStyle]
+	vertical := colorize(box.vertical, layout.BorderColor)
+
+	cells, err := padCells(cells, layout, lineIdx)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range cells {
+		if !layout.Compact {
+			cells[i] = " " + cells[i] + " "
+		}
+		cells[i] = colorize(cells[i], color)
+	}
+
+	return vertical + strings.Join(cells, vertical) + vertical, nil
+}
+
+// Wrap a string in an ANSI escape sequence setting the color, or leave it as is if there is no color
+func colorize(s string, color Color) string {
+	if color == "" {
+		return s
+	}
+
+	return "\x1b[" + string(color) + "m" + s + "\x1b[0m"
+}
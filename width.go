@@ -0,0 +1,155 @@
+package mdtable
+
+import (
+	"slices"
+	"unicode"
+)
+
+const zeroWidthJoiner = '\u200d'
+
+// States of an ANSI escape sequence while measuring a string
+const (
+	outsideEscape = iota
+	// right after ESC
+	escapeStart
+	// ESC [ ... terminated by a byte in the range @ to ~, e.g. SGR colors
+	csiSequence
+	// ESC ] ... terminated by BEL or ESC \, e.g. hyperlinks
+	oscSequence
+)
+
+// Ranges of characters taking two cells in a monospace font: East Asian Wide and Fullwidth characters and emoji with an emoji presentation
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0}, {0x23F3, 0x23F3},
+	{0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA},
+	{0x26F2, 0x26F3}, {0x26F5, 0x26F5}, {0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF}, {0xA960, 0xA97F}, {0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18CFF}, {0x1AFF0, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A}, {0x1F1E6, 0x1F1FF}, {0x1F200, 0x1F2FF}, {0x1F300, 0x1F320}, {0x1F32D, 0x1F335},
+	{0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA}, {0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0},
+	{0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E}, {0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D},
+	{0x1F54B, 0x1F54E}, {0x1F550, 0x1F567}, {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2}, {0x1F6D5, 0x1F6D7},
+	{0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC}, {0x1F7E0, 0x1F7EB}, {0x1F7F0, 0x1F7F0},
+	{0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// Get the number of cells a string takes in a monospace font. East Asian Wide characters and emoji take 2 cells,
+// combining marks, zero-width characters and emoji modifiers take none and every grapheme cluster is counted once:
+// characters joined with a zero-width joiner and pairs of regional indicators (flags) take the width of their first character.
+// ANSI escape sequences such as colors take no cells.
+func displayWidth(s string) int {
+	if isPrintableASCII(s) {
+		return len(s)
+	}
+
+	width := 0
+	joined := false
+	regionalIndicators := 0
+	escape := outsideEscape
+
+	for _, r := range s {
+		switch escape {
+		case escapeStart:
+			switch r {
+			case '[':
+				escape = csiSequence
+			case ']':
+				escape = oscSequence
+			default:
+				// two character sequence, or the end of an OSC sequence
+				escape = outsideEscape
+			}
+			continue
+		case csiSequence:
+			if r >= '@' && r <= '~' {
+				escape = outsideEscape
+			}
+			continue
+		case oscSequence:
+			if r == '\a' {
+				escape = outsideEscape
+			} else if r == '\x1b' {
+				escape = escapeStart
+			}
+			continue
+		}
+
+		if r == '\x1b' {
+			escape = escapeStart
+			continue
+		}
+
+		switch {
+		case joined:
+			// part of the cluster started before the zero-width joiner
+			joined = false
+		case r == zeroWidthJoiner:
+			joined = true
+		case isZeroWidth(r):
+		case isRegionalIndicator(r):
+			// every second regional indicator completes a flag
+			if regionalIndicators%2 == 0 {
+				width += 2
+			}
+			regionalIndicators++
+			continue
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+
+		regionalIndicators = 0
+	}
+
+	return width
+}
+
+// check whether a string only holds printable ASCII characters, which take one cell each
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// check whether a character takes no cells on its own
+func isZeroWidth(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc) ||
+		// emoji skin tone modifiers only change the emoji before them
+		(r >= 0x1F3FB && r <= 0x1F3FF)
+}
+
+// check whether a character is a regional indicator, two of them form a flag
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// check whether a character takes two cells
+func isWide(r rune) bool {
+	if r < wideRanges[0][0] {
+		return false
+	}
+
+	_, found := slices.BinarySearchFunc(wideRanges, r, func(wideRange [2]rune, target rune) int {
+		switch {
+		case wideRange[1] < target:
+			return -1
+		case wideRange[0] > target:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return found
+}